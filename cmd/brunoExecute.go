@@ -2,27 +2,98 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
+	"unicode/utf8"
 
+	"github.com/SAP/jenkins-library/pkg/bruno"
 	"github.com/SAP/jenkins-library/pkg/command"
+	piperConfig "github.com/SAP/jenkins-library/pkg/config"
+	piperhttp "github.com/SAP/jenkins-library/pkg/http"
 	"github.com/SAP/jenkins-library/pkg/log"
 	"github.com/SAP/jenkins-library/pkg/piperutils"
 	"github.com/SAP/jenkins-library/pkg/telemetry"
+	"github.com/ghodss/yaml"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/pkg/errors"
 )
 
+const (
+	brunoVersionCheckMaxRetries = 3
+	brunoCliPackage             = "@usebruno/cli"
+	defaultNpmGlobalPrefix      = "~/.npm-global"
+	brunoResultsFile            = "brunoExecute_results.json"
+	brunoMergedJunitFile        = "brunoExecute_junit.xml"
+	brunoDefaultMaxBodyLogBytes = 4096
+)
+
+// brunoVersionCheckRetryDelay is the delay before the first retry of the version check, a variable to be overridden in tests
+var brunoVersionCheckRetryDelay = 250 * time.Millisecond
+
+// brunoInstallRetryDelay is the delay before the first retry of the installation, a variable to be overridden in tests
+var brunoInstallRetryDelay = time.Second
+
+// brunoMaxRetryDelay caps the delay between the attempts to run a collection, also with an exponential backoff
+const brunoMaxRetryDelay = 5 * time.Minute
+
+var brunoPreflightTimeout = 10 * time.Second
+
+// brunoTimeoutUnit is the unit of timeoutSeconds, a variable to be overridden in tests
+var brunoTimeoutUnit = time.Second
+
+var (
+	npmAddedPackagesRegex = regexp.MustCompile(`added (\d+) packages?`)
+	brunoVersionRegex     = regexp.MustCompile(`^[0-9A-Za-z.+\-_^~]+$`)
+)
+
 type brunoExecuteUtils interface {
 	RunExecutable(executable string, params ...string) error
+	RunExecutableInBackground(executable string, params ...string) (command.Execution, error)
+	GetExitCode() int
+	AppendEnv(env []string)
+	SetDir(dir string)
+	Stdout(out io.Writer)
 	Getenv(key string) string
+	Environ() []string
+	Open(name string) (io.ReadWriteCloser, error)
+	FileWrite(path string, content []byte, perm os.FileMode) error
+	WriteFile(filename string, data []byte, perm os.FileMode) error
+	FileExists(filename string) (bool, error)
+	DirExists(path string) (bool, error)
+	FileRead(path string) ([]byte, error)
+	FileRemove(path string) error
+	MkdirAll(path string, perm os.FileMode) error
+	TempDir(dir, pattern string) (string, error)
+	Glob(pattern string) ([]string, error)
+	RemoveAll(path string) error
+	CloneGitRepository(url, branch, directory string) error
+	SetOptions(options piperhttp.ClientOptions)
+	SendRequest(method, url string, body io.Reader, header http.Header, cookies []*http.Cookie) (*http.Response, error)
+	GetVaultClient() piperConfig.VaultClient
 }
 
 type brunoExecuteUtilsBundle struct {
 	*command.Command
 	*piperutils.Files
+	*piperhttp.Client
 }
 
 func newBrunoExecuteUtils() brunoExecuteUtils {
@@ -40,110 +111,2035 @@ func newBrunoExecuteUtils() brunoExecuteUtils {
 				},
 			},
 		},
-		Files: &piperutils.Files{},
+		Files:  &piperutils.Files{},
+		Client: &piperhttp.Client{},
+	}
+	// Reroute command output to logging framework
+	utils.Stdout(log.Writer())
+	utils.Stderr(log.Writer())
+	return &utils
+}
+
+// GetVaultClient returns the Vault client of the step, nil if Vault is not configured
+func (b *brunoExecuteUtilsBundle) GetVaultClient() piperConfig.VaultClient {
+	return piperConfig.GlobalVaultClient()
+}
+
+func brunoExecute(config brunoExecuteOptions, _ *telemetry.CustomData, commonPipelineEnvironment *brunoExecuteCommonPipelineEnvironment, influx *brunoExecuteInflux) {
+	utils := newBrunoExecuteUtils()
+
+	influx.step_data.fields.bruno = false
+	err := runBrunoExecute(&config, utils, commonPipelineEnvironment, influx)
+	if err != nil {
+		log.Entry().WithError(err).Fatal("step execution failed")
+	}
+	influx.step_data.fields.bruno = true
+}
+
+func runBrunoExecute(config *brunoExecuteOptions, utils brunoExecuteUtils, commonPipelineEnvironment *brunoExecuteCommonPipelineEnvironment, influx *brunoExecuteInflux) error {
+	if config.SpecFile != "" {
+		if err := applyBrunoSpecFile(config, utils); err != nil {
+			return err
+		}
+	}
+	if err := checkBrunoHome(config, utils); err != nil {
+		return err
+	}
+	if config.BrunoCollection == "" && len(config.BrunoCollections) == 0 && len(config.CollectionConfigs) == 0 {
+		// templated pipelines may only know the collection at runtime
+		config.BrunoCollection = utils.Getenv("BRUNO_COLLECTION")
+	}
+	if config.EnvVarsFile != "" {
+		envVars, err := readBrunoEnvVarsFile(config.EnvVarsFile, utils)
+		if err != nil {
+			return err
+		}
+		config.EnvVars = append(config.EnvVars, envVars...)
+	}
+	if config.EnvVarPrefix != "" {
+		config.EnvVars = append(config.EnvVars, brunoPrefixedEnvVars(config.EnvVarPrefix, config.EnvVars, utils.Environ())...)
+	}
+	// envVars commonly carry credentials, which must not show up in logged commands
+	for _, value := range brunoEnvVarValues(config) {
+		log.RegisterSecret(value)
+	}
+	// the URL may also be set by specFile
+	if config.ResultsWebhookURL != "" {
+		log.RegisterSecret(config.ResultsWebhookURL)
+	}
+	log.Entry().Infof("effective configuration: %v", brunoEffectiveConfig(config))
+
+	if config.CollectionGitURL != "" {
+		cloneDir, err := cloneBrunoCollection(config, utils)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := utils.RemoveAll(cloneDir); err != nil {
+				log.Entry().WithError(err).Warnf("failed to remove the cloned Bruno collection '%v'", cloneDir)
+			}
+		}()
+	}
+
+	collections, err := resolveBrunoCollections(config)
+	if err != nil {
+		return err
+	}
+	influx.step_data.tags.environment = brunoRunEnvironments(config, collections)
+	for _, collection := range collections {
+		for _, value := range brunoEnvVarValues(&brunoExecuteOptions{EnvVars: collection.envVars}) {
+			log.RegisterSecret(value)
+		}
+	}
+	if err := checkBrunoForbiddenEnvironments(config, collections); err != nil {
+		return err
+	}
+
+	if err := resolveBrunoDataFile(config); err != nil {
+		return err
+	}
+
+	if err := validateBrunoSandboxMode(config.SandboxMode); err != nil {
+		return err
+	}
+	if config.SuccessPattern != "" {
+		if _, err := regexp.Compile(config.SuccessPattern); err != nil {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return errors.Wrapf(err, "invalid successPattern '%v'", config.SuccessPattern)
+		}
+	}
+
+	if config.Delay < 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("invalid delay %v, the value must not be negative", config.Delay)
+	}
+	if config.Retries < 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("invalid retries %v, the value must not be negative", config.Retries)
+	}
+	if config.RetryDelaySeconds < 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("invalid retryDelaySeconds %v, the value must not be negative", config.RetryDelaySeconds)
+	}
+	if config.RequestTimeoutMs < 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("invalid requestTimeoutMs %v, the value must not be negative", config.RequestTimeoutMs)
+	}
+	if config.IterationCount < 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("invalid iterationCount %v, the value must not be negative", config.IterationCount)
+	}
+	if config.BailAfter < 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("invalid bailAfter %v, the value must not be negative", config.BailAfter)
+	}
+
+	resolveBrunoInsecureHosts(config)
+	resolveBrunoParallelWorkers(config)
+	if config.HarOutputPath != "" {
+		log.Entry().Warnf("harOutputPath '%v' is ignored, the Bruno CLI cannot write HTTP archives", config.HarOutputPath)
+	}
+	if config.SlowThresholdMs > 0 && config.ReporterJSON == "" {
+		log.Entry().Warn("slowThresholdMs requires reporterJson to be set, skipping the check for slow requests")
+	}
+	if len(config.AllowedFailures) > 0 && config.ReporterJSON == "" {
+		log.Entry().Warn("allowedFailures requires reporterJson to be set, all failures fail the step")
+	}
+	if err := validateBrunoTLSOptions(config, utils); err != nil {
+		return err
+	}
+
+	if config.EnvFile != "" {
+		if err := validateBrunoEnvFile(brunoWorkingDirPath(config, config.EnvFile), utils); err != nil {
+			return err
+		}
+	}
+	if config.ReporterHtmlTemplate != "" {
+		if err := validateBrunoHtmlTemplate(config, utils); err != nil {
+			return err
+		}
+	}
+
+	for _, collection := range collections {
+		collectionConfig := collection.mergedConfig(config)
+		if len(config.TestFiles) > 0 && (collectionConfig.Tags != "" || collectionConfig.ExcludeTags != "") {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return errors.New("testFiles cannot be used together with tags or excludeTags, remove one of them")
+		}
+	}
+
+	for _, collection := range collections {
+		if err := checkBrunoCollectionExists(config, collection.path, utils); err != nil {
+			return err
+		}
+	}
+	if config.ListOnly != "" {
+		return listBrunoCollections(config, collections, utils)
+	}
+
+	if config.RequireCleanCollection {
+		for _, collection := range collections {
+			if err := checkCleanBrunoCollection(brunoWorkingDirPath(config, collection.path), utils); err != nil {
+				return err
+			}
+		}
+	}
+
+	if config.PreflightURL != "" && !config.DryRun {
+		if err := checkBrunoPreflightURL(config, utils); err != nil {
+			return err
+		}
+	}
+
+	if config.SkipVersionLogging {
+		if config.MinNodeVersion != "" {
+			log.Entry().Warn("minNodeVersion is not checked since skipVersionLogging is enabled")
+		}
+	} else if err := logVersionsBruno(config, utils); err != nil {
+		return err
+	}
+
+	if !config.DryRun {
+		installed, err := isBrunoInstalled(config, utils)
+		if err != nil {
+			return err
+		}
+		if !installed {
+			err = ensureWritableNpmGlobalPrefix(config, utils)
+			if err != nil {
+				return err
+			}
+			if config.CleanupInstall {
+				defer uninstallBruno(config, utils)
+			}
+			err = installBruno(config, utils)
+			if err != nil {
+				return err
+			}
+			if err := checkBrunoExecutable(config, utils); err != nil {
+				return err
+			}
+		}
+	}
+
+	brunoPath := brunoExecutablePath(config, utils)
+	if config.RequestTimeoutMs > 0 && !config.DryRun {
+		checkBrunoRequestTimeout(config, brunoPath, utils)
+	}
+	if config.WorkingDirectory != "" {
+		utils.SetDir(config.WorkingDirectory)
+	}
+	if proxyEnv := brunoProxyEnv(config); len(proxyEnv) > 0 {
+		utils.AppendEnv(proxyEnv)
+	}
+	results := brunoRunResults{}
+	if err := runBrunoCollections(config, collections, brunoPath, utils, &results); err != nil {
+		return err
+	}
+	if config.DryRun {
+		return nil
+	}
+
+	influx.step_data.fields.bruno_tests_total = results.metrics.Tests
+	influx.step_data.fields.bruno_tests_failed = results.metrics.FailedTests
+	influx.step_data.fields.bruno_requests_total = results.metrics.Requests
+	influx.step_data.fields.bruno_duration_ms = int(results.metrics.DurationMs)
+	if summaryErr := writeBrunoSummary(results.metrics, results.runErr, commonPipelineEnvironment); summaryErr != nil {
+		return summaryErr
+	}
+	if err := writeBrunoResults(&results, utils); err != nil {
+		return err
+	}
+	if config.MergeJunitReports {
+		mergedReport, err := mergeBrunoJunitReports(config, results.reports, utils)
+		if err != nil {
+			return err
+		}
+		results.reports = append(results.reports, mergedReport...)
+	}
+	if err := piperutils.PersistReportsAndLinks("brunoExecute", "", utils, results.reports, nil); err != nil {
+		return errors.Wrap(err, "failed to persist the Bruno reports")
+	}
+	if config.CsvResultsOutput != "" {
+		if err := utils.FileWrite(config.CsvResultsOutput, results.csv.Bytes(), 0o644); err != nil {
+			return errors.Wrapf(err, "failed to write CSV results to '%v'", config.CsvResultsOutput)
+		}
+	}
+	if config.AssertionsOutput != "" {
+		if err := utils.FileWrite(config.AssertionsOutput, results.assertions.Bytes(), 0o644); err != nil {
+			return errors.Wrapf(err, "failed to write assertion results to '%v'", config.AssertionsOutput)
+		}
+	}
+	if config.SummaryMarkdownPath != "" {
+		var summary bytes.Buffer
+		if err := bruno.WriteMarkdownSummary(&summary, results.collections); err != nil {
+			return err
+		}
+		if err := utils.FileWrite(config.SummaryMarkdownPath, summary.Bytes(), 0o644); err != nil {
+			return errors.Wrapf(err, "failed to write the Markdown summary to '%v'", config.SummaryMarkdownPath)
+		}
+	}
+	if config.ResultsWebhookURL != "" {
+		// the results are reported on a best effort basis, an unavailable webhook must not fail the tests
+		if err := postBrunoResultsWebhook(config, results.collections, utils); err != nil {
+			log.Entry().WithError(err).Warn("failed to post the Bruno results to the webhook")
+		}
+	}
+	if results.runErr != nil {
+		if config.FailOnError {
+			if len(collections) == 1 {
+				return errors.Wrap(results.runErr, "The execution of the Bruno tests failed, see the log for details.")
+			}
+			return fmt.Errorf("The execution of the Bruno tests failed for the collections %v, see the log for details.", strings.Join(results.failedCollections, ", "))
+		}
+		log.Entry().WithError(results.runErr).Warn("Bruno tests failed, but failOnError is set to false")
+	}
+	if config.FailOnNoTests {
+		if err := checkBrunoTestsExecuted(&results); err != nil {
+			return err
+		}
+	}
+	if config.MaxP95ResponseTimeMs > 0 {
+		p95 := bruno.Percentile(results.responseTimes, 95)
+		influx.step_data.fields.bruno_p95_response_time_ms = int(p95)
+		log.Entry().Infof("Bruno p95 response time: %vms (budget %vms)", p95, config.MaxP95ResponseTimeMs)
+		if p95 > int64(config.MaxP95ResponseTimeMs) {
+			log.SetErrorCategory(log.ErrorTest)
+			return fmt.Errorf("the p95 response time of %vms exceeds the budget of %vms", p95, config.MaxP95ResponseTimeMs)
+		}
+	}
+	if config.FailOnSlow && results.slowRequests > 0 {
+		log.SetErrorCategory(log.ErrorTest)
+		return fmt.Errorf("%v requests were slower than %vms", results.slowRequests, config.SlowThresholdMs)
+	}
+
+	return nil
+}
+
+// checkBrunoTestsExecuted fails if the JSON reports contain no requests, which the Bruno CLI does not consider an error
+func checkBrunoTestsExecuted(results *brunoRunResults) error {
+	if !results.hasReport {
+		log.Entry().Warn("failOnNoTests requires reporterJson to be set, skipping the check for executed requests")
+		return nil
+	}
+	if results.metrics.Requests == 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return errors.New("no requests were executed, check the collection and the tags and excludeTags filters")
+	}
+	return nil
+}
+
+// runBrunoCollections runs all collections. With outputFile, the output of Bruno CLI is written to it as well,
+// also if a collection fails.
+func runBrunoCollections(config *brunoExecuteOptions, collections []brunoCollection, brunoPath string, utils brunoExecuteUtils, results *brunoRunResults) error {
+	var output bytes.Buffer
+	writers := []io.Writer{log.Writer()}
+	if config.OutputFile != "" && !config.DryRun {
+		if err := utils.FileWrite(config.OutputFile, []byte{}, 0o644); err != nil {
+			return errors.Wrapf(err, "failed to create the output file '%v'", config.OutputFile)
+		}
+		writers = append(writers, &output)
+	}
+	if config.SuccessPattern != "" && !config.DryRun {
+		writers = append(writers, &results.output)
+	}
+	if len(writers) > 1 {
+		utils.Stdout(io.MultiWriter(writers...))
+		defer utils.Stdout(log.Writer())
+	}
+
+	var runErr error
+	for _, collection := range collections {
+		collectionConfig := collection.mergedConfig(config)
+		if runErr = runBrunoCollection(&collectionConfig, brunoPath, utils, results); runErr != nil {
+			break
+		}
+	}
+
+	if config.OutputFile != "" && !config.DryRun {
+		if err := utils.FileWrite(config.OutputFile, output.Bytes(), 0o644); err != nil {
+			return errors.Wrapf(err, "failed to write the output of Bruno CLI to '%v'", config.OutputFile)
+		}
+	}
+	return runErr
+}
+
+// brunoRunResults aggregates the outcome of the runs of all collections
+type brunoRunResults struct {
+	metrics           bruno.Metrics
+	collections       []bruno.CollectionSummary
+	allureResults     int
+	csv               bytes.Buffer
+	assertions        bytes.Buffer
+	reports           []piperutils.Path
+	exitCode          int
+	hasReport         bool
+	failedRequests    []string
+	responseTimes     []int64
+	failedCollections []string
+	slowRequests      int
+	output            bytes.Buffer
+	runErr            error
+}
+
+// checkBrunoPreflightURL fails fast if the tested environment is not reachable.
+// Any response below 500 proves connectivity, e.g. a base URL may well respond with 404 or 405.
+func checkBrunoPreflightURL(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
+	target, err := url.Parse(config.PreflightURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("invalid preflightUrl '%v', an http or https URL is required", config.PreflightURL)
+	}
+	proxy, err := brunoPreflightProxy(config, target, utils)
+	if err != nil {
+		return err
+	}
+	utils.SetOptions(piperhttp.ClientOptions{
+		MaxRequestDuration:        brunoPreflightTimeout,
+		TransportTimeout:          brunoPreflightTimeout,
+		MaxRetries:                -1,
+		TransportSkipVerification: config.Insecure,
+		TransportProxy:            proxy,
+	})
+
+	log.Entry().Infof("checking the connectivity to '%v'", config.PreflightURL)
+	response, err := utils.SendRequest(http.MethodHead, config.PreflightURL, nil, nil, nil)
+	if response != nil && response.Body != nil {
+		response.Body.Close()
+	}
+	if response == nil || response.StatusCode == 0 {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		return errors.Wrapf(err, "the preflight URL '%v' is not reachable, skipping the Bruno tests. Check that the environment is up", config.PreflightURL)
+	}
+	if response.StatusCode >= http.StatusInternalServerError {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		return fmt.Errorf("the preflight URL '%v' responded with '%v', skipping the Bruno tests. Check that the environment is up", config.PreflightURL, response.Status)
+	}
+	return nil
+}
+
+// postBrunoResultsWebhook posts the status and request counts of the collections as JSON to resultsWebhookUrl
+func postBrunoResultsWebhook(config *brunoExecuteOptions, collections []bruno.CollectionSummary, utils brunoExecuteUtils) error {
+	target, err := url.Parse(config.ResultsWebhookURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("invalid resultsWebhookUrl, an http or https URL is required")
+	}
+	payload, err := json.Marshal(bruno.NewWebhookPayload(collections))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the webhook payload")
+	}
+	proxy, err := brunoPreflightProxy(config, target, utils)
+	if err != nil {
+		return err
+	}
+	utils.SetOptions(piperhttp.ClientOptions{
+		MaxRequestDuration:        brunoPreflightTimeout,
+		TransportTimeout:          brunoPreflightTimeout,
+		MaxRetries:                -1,
+		TransportSkipVerification: config.Insecure,
+		TransportProxy:            proxy,
+	})
+
+	log.Entry().Infof("posting the Bruno results to the webhook at '%v'", target.Host)
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	response, err := utils.SendRequest(http.MethodPost, config.ResultsWebhookURL, bytes.NewReader(payload), header, nil)
+	if response != nil && response.Body != nil {
+		response.Body.Close()
+	}
+	return err
+}
+
+// brunoPreflightProxy returns the proxy for the preflight and webhook requests like the Bruno CLI would use it,
+// from the step configuration or otherwise the environment of the agent
+func brunoPreflightProxy(config *brunoExecuteOptions, target *url.URL, utils brunoExecuteUtils) (*url.URL, error) {
+	if config.BrunoNoProxy {
+		return nil, nil
+	}
+	brunoProxyValue := func(value, name string) string {
+		if value != "" {
+			return value
+		}
+		if value = utils.Getenv(name); value != "" {
+			return value
+		}
+		return utils.Getenv(strings.ToLower(name))
+	}
+	noProxy := brunoProxyValue(config.NoProxy, "NO_PROXY")
+	for _, host := range strings.Split(noProxy, ",") {
+		host = strings.TrimPrefix(strings.TrimSpace(host), ".")
+		if host == "*" || (host != "" && (target.Hostname() == host || strings.HasSuffix(target.Hostname(), "."+host))) {
+			return nil, nil
+		}
+	}
+	proxy := brunoProxyValue(config.HttpProxy, "HTTP_PROXY")
+	if target.Scheme == "https" {
+		proxy = brunoProxyValue(config.HttpsProxy, "HTTPS_PROXY")
+	}
+	if proxy == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return nil, errors.Wrapf(err, "invalid proxy '%v'", proxy)
+	}
+	return proxyURL, nil
+}
+
+// brunoProxyEnv returns the proxy environment variables for the Bruno execution in upper and lower case.
+// Empty values are skipped so that a proxy configured on the agent is kept.
+func brunoProxyEnv(config *brunoExecuteOptions) []string {
+	env := []string{}
+	for name, value := range map[string]string{"HTTP_PROXY": config.HttpProxy, "HTTPS_PROXY": config.HttpsProxy, "NO_PROXY": config.NoProxy} {
+		if value != "" {
+			env = append(env, name+"="+value, strings.ToLower(name)+"="+value)
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+// cloneBrunoCollection shallow-clones collectionGitUrl into a temporary directory and points brunoCollection to it.
+// It returns the temporary directory, which has to be removed by the caller.
+func cloneBrunoCollection(config *brunoExecuteOptions, utils brunoExecuteUtils) (string, error) {
+	if len(config.BrunoCollections) > 0 || len(config.CollectionConfigs) > 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return "", errors.New("collectionGitUrl cannot be combined with brunoCollections or collectionConfigs, use collectionGitSubdir to select the collection")
+	}
+	cloneDir, err := utils.TempDir("", "bruno-collection")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create a temporary directory for the Bruno collection")
+	}
+	log.Entry().Infof("cloning the Bruno collection from '%v'", config.CollectionGitURL)
+	if err := utils.CloneGitRepository(config.CollectionGitURL, config.CollectionGitBranch, cloneDir); err != nil {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		if removeErr := utils.RemoveAll(cloneDir); removeErr != nil {
+			log.Entry().WithError(removeErr).Warnf("failed to remove the temporary directory '%v'", cloneDir)
+		}
+		return "", errors.Wrapf(err, "failed to clone the Bruno collection from '%v'", config.CollectionGitURL)
+	}
+	config.BrunoCollection = filepath.Join(cloneDir, config.CollectionGitSubdir)
+	return cloneDir, nil
+}
+
+// brunoCollection is a collection to run, with the options of collectionConfigs replacing the step options
+type brunoCollection struct {
+	path        string
+	tags        string
+	excludeTags string
+	environment string
+	envVars     []string
+}
+
+// mergedConfig returns a copy of the step options to run the collection with
+func (c brunoCollection) mergedConfig(config *brunoExecuteOptions) brunoExecuteOptions {
+	merged := *config
+	merged.BrunoCollection = c.path
+	if c.tags != "" {
+		merged.Tags = c.tags
+	}
+	if c.excludeTags != "" {
+		merged.ExcludeTags = c.excludeTags
+	}
+	if c.environment != "" {
+		merged.BrunoEnvironment = c.environment
+	}
+	if len(c.envVars) > 0 {
+		merged.EnvVars = append(slices.Clone(config.EnvVars), c.envVars...)
+	}
+	if brunoEnvironmentSuffix(&merged) != "" {
+		merged.ReporterJSON = brunoEnvironmentReportPath(&merged, merged.ReporterJSON)
+		merged.ReporterJunit = brunoEnvironmentReportPath(&merged, merged.ReporterJunit)
+		merged.ReporterHtml = brunoEnvironmentReportPath(&merged, merged.ReporterHtml)
+	}
+	return merged
+}
+
+// brunoEnvironmentSuffix returns the environment of the run with brunoEnvironments, which is added to the names of its reports
+func brunoEnvironmentSuffix(config *brunoExecuteOptions) string {
+	if len(config.BrunoEnvironments) == 0 {
+		return ""
+	}
+	return config.BrunoEnvironment
+}
+
+// brunoEnvironmentReportPath adds the environment of the run to the file name of a report, e.g. report.json becomes report-staging.json
+func brunoEnvironmentReportPath(config *brunoExecuteOptions, path string) string {
+	if path == "" {
+		return path
+	}
+	return insertBrunoReportSuffix(path, brunoEnvironmentSuffix(config))
+}
+
+// brunoReportFilenameSuffix returns reportFilenameSuffix without path separators, so that it cannot move the reports
+func brunoReportFilenameSuffix(config *brunoExecuteOptions) string {
+	return strings.NewReplacer("/", "", "\\", "").Replace(strings.TrimSpace(config.ReportFilenameSuffix))
+}
+
+// insertBrunoReportSuffix inserts the suffix before the file extension, e.g. report.json becomes report-42.json
+func insertBrunoReportSuffix(path, suffix string) string {
+	extension := filepath.Ext(path)
+	return strings.TrimSuffix(path, extension) + "-" + suffix + extension
+}
+
+// brunoRunName identifies the run of a collection in messages, including the environment with brunoEnvironments
+func brunoRunName(config *brunoExecuteOptions) string {
+	if environment := brunoEnvironmentSuffix(config); environment != "" {
+		return fmt.Sprintf("%v (%v)", config.BrunoCollection, environment)
+	}
+	return config.BrunoCollection
+}
+
+// resolveBrunoCollections returns collectionConfigs if set, then brunoCollections and falls back to the single brunoCollection otherwise.
+// With brunoEnvironments, each collection is returned once per environment.
+func resolveBrunoCollections(config *brunoExecuteOptions) ([]brunoCollection, error) {
+	collections := []brunoCollection{}
+	switch {
+	case len(config.CollectionConfigs) > 0:
+		var err error
+		if collections, err = parseBrunoCollectionConfigs(config.CollectionConfigs); err != nil {
+			return nil, err
+		}
+	case len(config.BrunoCollections) > 0:
+		for _, path := range config.BrunoCollections {
+			collections = append(collections, brunoCollection{path: path})
+		}
+	case config.BrunoCollection != "":
+		collections = append(collections, brunoCollection{path: config.BrunoCollection})
+	default:
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return nil, errors.New("no Bruno collection provided, set either brunoCollection, brunoCollections or the environment variable BRUNO_COLLECTION")
+	}
+	if len(config.BrunoEnvironments) == 0 {
+		return collections, nil
+	}
+
+	runs := []brunoCollection{}
+	for _, collection := range collections {
+		if collection.environment != "" {
+			// the environment of collectionConfigs is more specific
+			runs = append(runs, collection)
+			continue
+		}
+		for _, environment := range config.BrunoEnvironments {
+			run := collection
+			run.environment = environment
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// brunoRunEnvironments returns the distinct environments the collections are run in, joined by commas
+func brunoRunEnvironments(config *brunoExecuteOptions, collections []brunoCollection) string {
+	environments := []string{}
+	for _, collection := range collections {
+		environment := config.BrunoEnvironment
+		if collection.environment != "" {
+			environment = collection.environment
+		}
+		if environment != "" && !slices.Contains(environments, environment) {
+			environments = append(environments, environment)
+		}
+	}
+	return strings.Join(environments, ",")
+}
+
+func parseBrunoCollectionConfigs(collectionConfigs []map[string]interface{}) ([]brunoCollection, error) {
+	collections := []brunoCollection{}
+	for i, collectionConfig := range collectionConfigs {
+		path, ok := collectionConfig["collection"].(string)
+		if !ok || path == "" {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, fmt.Errorf("entry %v of collectionConfigs has no collection", i+1)
+		}
+		collection := brunoCollection{path: path}
+		for key, target := range map[string]*string{"tags": &collection.tags, "excludeTags": &collection.excludeTags, "brunoEnvironment": &collection.environment} {
+			value, exists := collectionConfig[key]
+			if !exists {
+				continue
+			}
+			text, ok := value.(string)
+			if !ok {
+				log.SetErrorCategory(log.ErrorConfiguration)
+				return nil, fmt.Errorf("%v of collection '%v' in collectionConfigs is not a string", key, path)
+			}
+			*target = text
+		}
+		if envVars, exists := collectionConfig["envVars"]; exists {
+			values, ok := envVars.([]interface{})
+			if !ok {
+				log.SetErrorCategory(log.ErrorConfiguration)
+				return nil, fmt.Errorf("envVars of collection '%v' in collectionConfigs is not a list", path)
+			}
+			for _, value := range values {
+				envVar, ok := value.(string)
+				if !ok {
+					log.SetErrorCategory(log.ErrorConfiguration)
+					return nil, fmt.Errorf("envVars of collection '%v' in collectionConfigs must only contain strings", path)
+				}
+				collection.envVars = append(collection.envVars, envVar)
+			}
+		}
+		collections = append(collections, collection)
+	}
+	return collections, nil
+}
+
+// checkBrunoCollectionExists fails early for missing collections, templated paths cannot be checked statically and are skipped
+func checkBrunoCollectionExists(config *brunoExecuteOptions, collection string, utils brunoExecuteUtils) error {
+	if strings.Contains(collection, "{{") {
+		return nil
+	}
+	path := brunoWorkingDirPath(config, collection)
+	exists, err := utils.DirExists(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check the Bruno collection '%v'", path)
+	}
+	if !exists {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("the Bruno collection '%v' does not exist", path)
+	}
+	return nil
+}
+
+// checkBrunoForbiddenEnvironments fails if any collection would be run with one of the forbiddenEnvironments
+func checkBrunoForbiddenEnvironments(config *brunoExecuteOptions, collections []brunoCollection) error {
+	for _, collection := range collections {
+		environment := collection.mergedConfig(config).BrunoEnvironment
+		if environment == "" {
+			continue
+		}
+		for _, forbidden := range config.ForbiddenEnvironments {
+			if strings.EqualFold(environment, forbidden) {
+				log.SetErrorCategory(log.ErrorConfiguration)
+				return fmt.Errorf("the Bruno environment '%v' of collection '%v' is forbidden by forbiddenEnvironments, no tests were run", environment, collection.path)
+			}
+		}
+	}
+	return nil
+}
+
+// listBrunoCollections logs the tags of the requests or the environments of the collections, which are read from their .bru files
+func listBrunoCollections(config *brunoExecuteOptions, collections []brunoCollection, utils brunoExecuteUtils) error {
+	listed := map[string]bool{}
+	for _, collection := range collections {
+		if listed[collection.path] {
+			// with brunoEnvironments, collections are contained once per environment
+			continue
+		}
+		listed[collection.path] = true
+		dir := brunoWorkingDirPath(config, trimBrunoCollectionPath(collection.path))
+		var names []string
+		var err error
+		switch config.ListOnly {
+		case "tags":
+			names, err = findBrunoCollectionTags(dir, utils)
+		case "envs":
+			names, err = findBrunoCollectionEnvironments(dir, utils)
+		default:
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return fmt.Errorf("invalid listOnly '%v', valid values are 'tags' and 'envs'", config.ListOnly)
+		}
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			log.Entry().Infof("no %v found in collection '%v'", config.ListOnly, collection.path)
+			continue
+		}
+		log.Entry().Infof("%v of collection '%v': %v", config.ListOnly, collection.path, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// findBrunoCollectionTags returns the sorted tags of all requests of the collection
+func findBrunoCollectionTags(dir string, utils brunoExecuteUtils) ([]string, error) {
+	files, err := utils.Glob(filepath.Join(dir, "**", "*.bru"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the requests of collection '%v'", dir)
+	}
+	tags := []string{}
+	for _, file := range files {
+		content, err := utils.FileRead(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read request '%v'", file)
+		}
+		for _, tag := range bruno.ParseRequestTags(string(content)) {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// findBrunoCollectionEnvironments returns the sorted names of the environments of the collection, one .bru file each
+func findBrunoCollectionEnvironments(dir string, utils brunoExecuteUtils) ([]string, error) {
+	files, err := utils.Glob(filepath.Join(dir, "environments", "*.bru"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find the environments of collection '%v'", dir)
+	}
+	environments := []string{}
+	for _, file := range files {
+		environments = append(environments, strings.TrimSuffix(filepath.Base(file), ".bru"))
+	}
+	sort.Strings(environments)
+	return environments, nil
+}
+
+// runBrunoWithRetries re-runs a failing Bruno execution up to config.Retries additional times
+func runBrunoWithRetries(config *brunoExecuteOptions, brunoPath string, runOptions []string, utils brunoExecuteUtils) error {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for attempt := 0; ; attempt++ {
+		err := runBrunoExecutable(config, brunoPath, runOptions, utils)
+		if err == nil || attempt >= config.Retries {
+			return err
+		}
+		base := brunoMaxRetryDelay
+		if config.RetryDelaySeconds < int(brunoMaxRetryDelay/time.Second) {
+			base = time.Duration(config.RetryDelaySeconds) * time.Second
+		}
+		delay := brunoRetryDelay(base, attempt, config.RetryBackoff, config.RetryJitter, random)
+		log.Entry().WithError(err).Warnf("Bruno tests of collection '%v' failed, retrying in %v (retry %v of %v)", config.BrunoCollection, delay, attempt+1, config.Retries)
+		time.Sleep(delay)
+	}
+}
+
+// checkBrunoSuccessPattern fails a run of Bruno CLI which exited successfully but whose output does not match successPattern
+func checkBrunoSuccessPattern(config *brunoExecuteOptions, output string) error {
+	// the pattern is validated before the runs
+	if regexp.MustCompile(config.SuccessPattern).MatchString(output) {
+		return nil
+	}
+	log.SetErrorCategory(log.ErrorTest)
+	return fmt.Errorf("Bruno CLI exited successfully, but its output does not match successPattern '%v'", config.SuccessPattern)
+}
+
+// brunoRetryDelay returns the delay before the retry following the given attempt, starting at 0.
+// The exponential backoff doubles the base delay with every attempt up to brunoMaxRetryDelay,
+// the jitter picks a random delay between half of and the full delay.
+func brunoRetryDelay(base time.Duration, attempt int, backoff string, jitter bool, random *rand.Rand) time.Duration {
+	delay := base
+	if backoff == "exponential" {
+		// doubling stepwise instead of shifting by attempt cannot overflow
+		for i := 0; i < attempt && delay > 0 && delay < brunoMaxRetryDelay; i++ {
+			delay *= 2
+		}
+	}
+	if delay > brunoMaxRetryDelay {
+		delay = brunoMaxRetryDelay
+	}
+	if jitter && delay > 0 {
+		delay = delay/2 + time.Duration(random.Int63n(int64(delay/2)+1))
+	}
+	return delay
+}
+
+// runBrunoExecutable runs the Bruno CLI and terminates it if it does not finish within timeoutSeconds
+func runBrunoExecutable(config *brunoExecuteOptions, brunoPath string, runOptions []string, utils brunoExecuteUtils) error {
+	if config.TimeoutSeconds <= 0 {
+		return utils.RunExecutable(brunoPath, runOptions...)
+	}
+	timeout := time.Duration(config.TimeoutSeconds) * brunoTimeoutUnit
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	execution, err := utils.RunExecutableInBackground(brunoPath, runOptions...)
+	if err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- execution.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if killErr := execution.Kill(); killErr != nil {
+			log.Entry().WithError(killErr).Warn("failed to terminate the Bruno CLI")
+		}
+		<-done
+		log.SetErrorCategory(log.ErrorTest)
+		return fmt.Errorf("the Bruno tests of collection '%v' did not finish within %vs and were terminated", config.BrunoCollection, config.TimeoutSeconds)
+	}
+}
+
+// runBrunoAuthSmokeRequest runs a single request of the collection to verify connectivity and authentication
+// before spending time on the whole suite, which is skipped if the smoke request fails.
+func runBrunoAuthSmokeRequest(config *brunoExecuteOptions, brunoPath string, utils brunoExecuteUtils) error {
+	request := config.AuthSmokeRequest
+	if filepath.Ext(request) != ".bru" {
+		request += ".bru"
+	}
+	smokeOptions := append([]string{brunoSubcommand(config), filepath.Join(trimBrunoCollectionPath(config.BrunoCollection), request)}, buildBrunoEnvironmentOptions(config)...)
+	if config.Insecure {
+		smokeOptions = append(smokeOptions, "--insecure")
+	}
+
+	if config.DryRun {
+		log.Entry().Infof("dry run, skipping the Bruno auth smoke request: %v %v", brunoPath, strings.Join(smokeOptions, " "))
+		return nil
+	}
+	log.Entry().Infof("running auth smoke request '%v' of collection '%v'", config.AuthSmokeRequest, config.BrunoCollection)
+	if err := utils.RunExecutable(brunoPath, smokeOptions...); err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return errors.Wrapf(err, "the auth smoke request '%v' of collection '%v' failed, skipping the Bruno tests. Check connectivity and the credentials of the environment", config.AuthSmokeRequest, config.BrunoCollection)
+	}
+	return nil
+}
+
+// validateBrunoSandboxMode rejects sandbox modes unknown to Bruno CLI before it is invoked, an empty mode omits --sandbox
+func validateBrunoSandboxMode(sandboxMode string) error {
+	switch sandboxMode {
+	case "", "safe", "developer":
+		return nil
+	default:
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("invalid sandboxMode '%v', valid values are 'safe' and 'developer'", sandboxMode)
+	}
+}
+
+// resolveBrunoInsecureHosts falls back to insecure for insecureHosts, since bru run --insecure applies to all hosts
+func resolveBrunoInsecureHosts(config *brunoExecuteOptions) {
+	if len(config.InsecureHosts) == 0 || config.Insecure {
+		return
+	}
+	log.Entry().Warnf("the Bruno CLI does not support insecure connections to individual hosts, disabling the TLS verification for all hosts instead of only %v. Consider caCert to trust their certificates", strings.Join(config.InsecureHosts, ", "))
+	config.Insecure = true
+}
+
+// resolveBrunoParallelWorkers enables parallel for parallelWorkers. bru run --parallel takes no number of workers,
+// so the requests run with the default concurrency of the Bruno CLI.
+func resolveBrunoParallelWorkers(config *brunoExecuteOptions) {
+	if config.ParallelWorkers <= 0 {
+		return
+	}
+	if !config.Parallel {
+		log.Entry().Infof("parallelWorkers is set to %v, enabling parallel", config.ParallelWorkers)
+		config.Parallel = true
+	}
+	log.Entry().Warnf("the Bruno CLI does not support limiting the number of parallel requests, running them with its default concurrency instead of %v workers", config.ParallelWorkers)
+}
+
+// validateBrunoTLSOptions ensures that the files referenced by the TLS options exist before Bruno CLI is invoked
+func validateBrunoTLSOptions(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
+	if config.ClientCertConfig != "" {
+		if err := checkBrunoFileExists(brunoWorkingDirPath(config, config.ClientCertConfig), "client certificate configuration", utils); err != nil {
+			return err
+		}
+		if config.Insecure {
+			log.Entry().Warn("clientCertConfig is set together with insecure, the server certificates are not verified")
+		}
+	}
+	if config.CaCert != "" {
+		if err := checkBrunoFileExists(brunoWorkingDirPath(config, config.CaCert), "CA certificate", utils); err != nil {
+			return err
+		}
+		if config.Insecure {
+			log.Entry().Warn("caCert is ignored because insecure disables the TLS verification")
+		}
+	}
+	return nil
+}
+
+func checkBrunoFileExists(path, description string, utils brunoExecuteUtils) error {
+	exists, err := utils.FileExists(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check the %v '%v'", description, path)
+	}
+	if !exists {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("the %v '%v' does not exist", description, path)
+	}
+	return nil
+}
+
+// validateBrunoHtmlTemplate checks that a template file exists. The template is ignored, since bru run --reporter-html cannot be customized.
+func validateBrunoHtmlTemplate(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
+	template := config.ReporterHtmlTemplate
+	if strings.ContainsAny(template, "/"+string(filepath.Separator)) || filepath.Ext(template) != "" {
+		if err := checkBrunoFileExists(brunoWorkingDirPath(config, template), "reporterHtmlTemplate", utils); err != nil {
+			return err
+		}
+	}
+	log.Entry().Warnf("the Bruno CLI does not support templates for the HTML report, ignoring reporterHtmlTemplate '%v'", template)
+	return nil
+}
+
+// validateBrunoEnvFile ensures that the environment file can be read by Bruno CLI.
+// Files in the .bru format need to be readable text, all other files need to be valid JSON.
+func validateBrunoEnvFile(envFile string, utils brunoExecuteUtils) error {
+	if err := checkBrunoFileExists(envFile, "environment file", utils); err != nil {
+		return err
+	}
+	content, err := utils.FileRead(envFile)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return errors.Wrapf(err, "failed to read the environment file '%v'", envFile)
+	}
+	if filepath.Ext(envFile) == ".bru" {
+		if !utf8.Valid(content) {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return fmt.Errorf("the environment file '%v' is not a valid text file", envFile)
+		}
+		return nil
+	}
+	var env interface{}
+	if err := json.Unmarshal(content, &env); err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return errors.Wrapf(err, "the environment file '%v' is not valid JSON", envFile)
+	}
+	return nil
+}
+
+// resolveBrunoDataFile ensures that at most one data file is passed to Bruno CLI, which cannot consume both at once.
+// It warns about an iterationCount without data file, which is most likely a misconfiguration.
+func resolveBrunoDataFile(config *brunoExecuteOptions) error {
+	if config.CsvFilePath == "" && config.JSONFilePath == "" {
+		if config.IterationCount > 0 {
+			log.Entry().Warnf("iterationCount %v is set without csvFilePath or jsonFilePath, all iterations run without data-driven input", config.IterationCount)
+		}
+		return nil
+	}
+	if config.CsvFilePath == "" || config.JSONFilePath == "" {
+		return nil
+	}
+	switch config.DataFilePrecedence {
+	case "csv":
+		log.Entry().Infof("both csvFilePath and jsonFilePath are set, using csvFilePath '%v' according to dataFilePrecedence", config.CsvFilePath)
+		config.JSONFilePath = ""
+	case "json":
+		log.Entry().Infof("both csvFilePath and jsonFilePath are set, using jsonFilePath '%v' according to dataFilePrecedence", config.JSONFilePath)
+		config.CsvFilePath = ""
+	default:
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return errors.New("csvFilePath and jsonFilePath cannot be used together, remove one of them or set dataFilePrecedence")
+	}
+	return nil
+}
+
+// runBrunoCollection runs a single collection and processes its JSON report.
+// A failing Bruno run is recorded in the results, only errors of the report processing are returned.
+func runBrunoCollection(config *brunoExecuteOptions, brunoPath string, utils brunoExecuteUtils, results *brunoRunResults) error {
+	runOptions, err := resolveRunOptions(config)
+	if err != nil {
+		return err
+	}
+	if err := resolveBrunoDataFilePaths(config); err != nil {
+		return err
+	}
+	if err := resolveBrunoEnvVars(config, utils); err != nil {
+		return err
+	}
+	if len(config.TestFiles) > 0 {
+		runOptions, err = resolveBrunoTestFiles(config, runOptions, utils)
+		if err != nil {
+			return err
+		}
+	}
+	resolveBrunoReporterPaths(config)
+
+	// Build additional options from config parameters
+	additionalOptions := buildBrunoOptions(config)
+	runOptions = append(runOptions, additionalOptions...)
+
+	if config.AuthSmokeRequest != "" {
+		if err := runBrunoAuthSmokeRequest(config, brunoPath, utils); err != nil {
+			results.collections = append(results.collections, bruno.CollectionSummary{Name: brunoRunName(config), RunFailed: true})
+			return recordBrunoRunFailure(config, runOptions, err, utils, results)
+		}
+	}
+
+	if config.DryRun {
+		log.Entry().Infof("dry run, skipping the Bruno execution of: %v %v", brunoPath, strings.Join(runOptions, " "))
+		return nil
+	}
+	if config.ReportsDirectory != "" {
+		if err := utils.MkdirAll(brunoWorkingDirPath(config, config.ReportsDirectory), 0o755); err != nil {
+			return errors.Wrapf(err, "failed to create the reports directory '%v'", config.ReportsDirectory)
+		}
+	}
+	if err := checkBrunoReporterDirs(config, runOptions, utils); err != nil {
+		return err
+	}
+	results.output.Reset()
+	err = runBrunoWithRetries(config, brunoPath, runOptions, utils)
+	if err == nil && config.SuccessPattern != "" {
+		err = checkBrunoSuccessPattern(config, results.output.String())
+	}
+	report := &brunoReport{}
+	var reportErr error
+	if config.ReporterJSON != "" {
+		if report, reportErr = readBrunoReport(config, utils, results.allureResults, &results.csv, &results.assertions); reportErr != nil {
+			if !brunoReportRequired(config) {
+				log.Entry().WithError(reportErr).Warnf("could not read Bruno JSON report '%v'", config.ReporterJSON)
+			}
+			report = &brunoReport{}
+		}
+	}
+	if err != nil && len(config.AllowedFailures) > 0 && config.ReporterJSON != "" {
+		err = allowBrunoFailures(config, err, report.failedRequests)
+	}
+	if err != nil {
+		if category := brunoFailureCategory(report.failureCause); category != log.ErrorUndefined {
+			log.SetErrorCategory(category)
+		}
+		if recordErr := recordBrunoRunFailure(config, runOptions, err, utils, results); recordErr != nil {
+			return recordErr
+		}
+	}
+	if config.ReporterJSON != "" {
+		results.hasReport = true
+		failedRequests := slices.DeleteFunc(slices.Clone(report.failedRequests), func(name string) bool {
+			return slices.Contains(config.AllowedFailures, name)
+		})
+		results.failedRequests = append(results.failedRequests, failedRequests...)
+	}
+	results.reports = append(results.reports, collectBrunoReports(config, runOptions, utils)...)
+
+	logBrunoReportMetrics(config, report.metrics)
+	results.metrics.Merge(report.metrics)
+	logBrunoSlowRequests(config, report.slowRequests)
+	results.slowRequests += len(report.slowRequests)
+	results.collections = append(results.collections, bruno.CollectionSummary{
+		Name:         brunoRunName(config),
+		RunFailed:    err != nil,
+		HasReport:    config.ReporterJSON != "",
+		Metrics:      report.metrics,
+		SlowRequests: report.slowRequests,
+	})
+	if config.SummarizeFailures || config.IncludeBodiesOnFailure {
+		logBrunoFailureSummary(config, report.failures)
+	}
+	if err := checkBrunoReportOptions(config); err != nil {
+		return err
+	}
+	if reportErr != nil && brunoReportRequired(config) {
+		return reportErr
+	}
+	results.allureResults += report.allureResults
+	if len(report.duplicates) > 0 {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("the Bruno collection contains duplicate request names: %v", strings.Join(report.duplicates, ", "))
+	}
+	results.responseTimes = append(results.responseTimes, report.responseTimes...)
+	return nil
+}
+
+// recordBrunoRunFailure records the failed run of a collection in the results, the remaining collections are run nevertheless
+func recordBrunoRunFailure(config *brunoExecuteOptions, runOptions []string, runErr error, utils brunoExecuteUtils, results *brunoRunResults) error {
+	log.Entry().WithError(runErr).Errorf("Bruno tests of collection '%v' failed", brunoRunName(config))
+	results.failedCollections = append(results.failedCollections, brunoRunName(config))
+	results.runErr = runErr
+	if results.exitCode == 0 {
+		results.exitCode = brunoExitCode(utils)
+	}
+	if config.FailOnError {
+		return writeBrunoCrashJUnit(config, runOptions, runErr, utils)
+	}
+	return nil
+}
+
+// brunoReport contains the outcome of a single collection taken from its JSON report
+type brunoReport struct {
+	metrics        bruno.Metrics
+	failedRequests []string
+	failureCause   string
+	slowRequests   []bruno.SlowRequest
+	failures       []string
+	duplicates     []string
+	responseTimes  []int64
+	allureResults  int
+}
+
+// readBrunoReport parses the JSON report of a collection once and feeds every result to the consumers enabled in the config.
+// The Allure results are numbered starting at firstAllureIndex, the CSV rows and assertion records are appended to csvResults and assertionResults.
+func readBrunoReport(config *brunoExecuteOptions, utils brunoExecuteUtils, firstAllureIndex int, csvResults, assertionResults *bytes.Buffer) (*brunoReport, error) {
+	reportFile, err := utils.Open(brunoWorkingDirPath(config, config.ReporterJSON))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open Bruno JSON report '%v'", config.ReporterJSON)
+	}
+	defer reportFile.Close()
+
+	if config.AllureOutputDir != "" {
+		if err := utils.MkdirAll(config.AllureOutputDir, 0o755); err != nil {
+			return nil, errors.Wrapf(err, "failed to create Allure results directory '%v'", config.AllureOutputDir)
+		}
+	}
+	var csvWriter *bruno.CSVWriter
+	if config.CsvResultsOutput != "" {
+		if csvResults.Len() == 0 {
+			if err := bruno.WriteCSVHeader(csvResults); err != nil {
+				return nil, err
+			}
+		}
+		csvWriter = bruno.NewCSVWriter(csvResults, config.MaskURLQueryParams)
+	}
+	var assertionWriter *bruno.AssertionWriter
+	if config.AssertionsOutput != "" {
+		assertionWriter = bruno.NewAssertionWriter(assertionResults, brunoEnvVarValues(config))
+	}
+	maxBodyBytes := brunoMaxBodyLogBytes(config)
+
+	report := &brunoReport{}
+	failedNames := bruno.FailedNames{}
+	classifier := bruno.FailureClassifier{}
+	duplicates := bruno.DuplicateNames{}
+	err = bruno.ParseReport(reportFile, func(result bruno.Result) error {
+		if slices.Contains(config.AllowedFailures, result.Name) {
+			report.metrics.AddAllowed(result)
+		} else {
+			report.metrics.Add(result)
+		}
+		failedNames.Add(result)
+		classifier.Add(result)
+		if config.SlowThresholdMs > 0 && result.Response.ResponseTime > int64(config.SlowThresholdMs) {
+			report.slowRequests = append(report.slowRequests, bruno.SlowRequest{Name: result.Name, ResponseTimeMs: result.Response.ResponseTime})
+		}
+		if (config.SummarizeFailures || config.IncludeBodiesOnFailure) && result.Failed() {
+			report.failures = append(report.failures, result.FailureSummary(config.MaskURLQueryParams, maxBodyBytes))
+		}
+		if config.FailOnDuplicateRequestNames {
+			duplicates.Add(result)
+		}
+		if config.MaxP95ResponseTimeMs > 0 {
+			report.responseTimes = append(report.responseTimes, result.Response.ResponseTime)
+		}
+		if csvWriter != nil {
+			if err := csvWriter.Write(result); err != nil {
+				return errors.Wrap(err, "failed to convert Bruno JSON report to CSV")
+			}
+		}
+		if assertionWriter != nil {
+			if err := assertionWriter.Write(result); err != nil {
+				return errors.Wrap(err, "failed to convert Bruno JSON report to assertion results")
+			}
+		}
+		if config.AllureOutputDir != "" {
+			if err := writeBrunoAllureResult(config, result, firstAllureIndex+report.allureResults, utils); err != nil {
+				return errors.Wrap(err, "failed to write Allure results")
+			}
+			report.allureResults++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if csvWriter != nil {
+		if err := csvWriter.Flush(); err != nil {
+			return nil, err
+		}
+	}
+	if config.AllureOutputDir != "" {
+		log.Entry().Infof("wrote %v Allure results to '%v'", report.allureResults, config.AllureOutputDir)
+	}
+	report.failedRequests = failedNames.Names()
+	report.failureCause = classifier.Cause
+	report.duplicates = duplicates.Names()
+	return report, nil
+}
+
+// brunoReportRequired returns true if an option relies on the JSON report, the step fails if the report cannot be read then
+func brunoReportRequired(config *brunoExecuteOptions) bool {
+	return config.AllureOutputDir != "" || config.CsvResultsOutput != "" || config.AssertionsOutput != "" || config.FailOnDuplicateRequestNames || config.MaxP95ResponseTimeMs > 0
+}
+
+// checkBrunoReportOptions rejects the options which rely on the JSON report if reporterJson is not set
+func checkBrunoReportOptions(config *brunoExecuteOptions) error {
+	if config.ReporterJSON != "" {
+		return nil
+	}
+	var option string
+	switch {
+	case config.AllureOutputDir != "":
+		option = "allureOutputDir"
+	case config.CsvResultsOutput != "":
+		option = "csvResultsOutput"
+	case config.AssertionsOutput != "":
+		option = "assertionsOutput"
+	case config.FailOnDuplicateRequestNames:
+		option = "failOnDuplicateRequestNames"
+	case config.MaxP95ResponseTimeMs > 0:
+		option = "maxP95ResponseTimeMs"
+	default:
+		return nil
+	}
+	log.SetErrorCategory(log.ErrorConfiguration)
+	return fmt.Errorf("%v requires reporterJson to be set", option)
+}
+
+// logBrunoSlowRequests logs the requests of the JSON report exceeding slowThresholdMs
+func logBrunoSlowRequests(config *brunoExecuteOptions, slowRequests []bruno.SlowRequest) {
+	for _, request := range slowRequests {
+		log.Entry().Warnf("request '%v' of collection '%v' took %vms, which is slower than %vms", request.Name, brunoRunName(config), request.ResponseTimeMs, config.SlowThresholdMs)
+	}
+}
+
+// allowBrunoFailures returns nil if all failed requests of the JSON report are allowedFailures and the error of the run otherwise.
+// Without any failed request in the report, the Bruno CLI failed for another reason and the error is kept.
+func allowBrunoFailures(config *brunoExecuteOptions, runErr error, failedRequests []string) error {
+	if len(failedRequests) == 0 {
+		return runErr
+	}
+	for _, name := range failedRequests {
+		if !slices.Contains(config.AllowedFailures, name) {
+			return runErr
+		}
+	}
+	log.Entry().Warnf("only allowed failures in collection '%v', ignoring the failed requests %v", brunoRunName(config), strings.Join(failedRequests, ", "))
+	return nil
+}
+
+func logBrunoReportMetrics(config *brunoExecuteOptions, metrics bruno.Metrics) {
+	if config.ReporterJSON == "" {
+		return
+	}
+	log.Entry().Infof("Bruno report: %v requests (%v failed), %v tests (%v failed), %v assertions (%v failed), %vms total response time",
+		metrics.Requests, metrics.FailedRequests, metrics.Tests, metrics.FailedTests, metrics.Assertions, metrics.FailedAssertions, metrics.DurationMs)
+	if metrics.AllowedFailures > 0 {
+		log.Entry().Infof("%v failed requests are allowedFailures and not counted as failed", metrics.AllowedFailures)
+	}
+}
+
+// brunoMaxBodyLogBytes returns the number of bytes of the request and response bodies to log for failed requests, 0 if they are not logged
+func brunoMaxBodyLogBytes(config *brunoExecuteOptions) int {
+	if !config.IncludeBodiesOnFailure {
+		return 0
+	}
+	if config.MaxBodyLogBytes <= 0 {
+		return brunoDefaultMaxBodyLogBytes
+	}
+	return config.MaxBodyLogBytes
+}
+
+// logBrunoFailureSummary logs the summaries of the failed requests of the JSON report
+func logBrunoFailureSummary(config *brunoExecuteOptions, failures []string) {
+	if config.ReporterJSON == "" {
+		log.Entry().Warn("summarizeFailures requires reporterJson to be set")
+		return
+	}
+	if len(failures) == 0 {
+		log.Entry().Infof("no failed requests in collection '%v'", brunoRunName(config))
+		return
+	}
+	log.Entry().Warnf("%v failed requests in collection '%v':", len(failures), brunoRunName(config))
+	for _, failure := range failures {
+		log.Entry().Warn("  " + failure)
+	}
+}
+
+// brunoSummary is written to the commonPipelineEnvironment to allow subsequent steps to gate on the test results
+type brunoSummary struct {
+	Status                  string `json:"status"`
+	Total                   int    `json:"total"`
+	Failed                  int    `json:"failed"`
+	DurationMs              int64  `json:"duration"`
+	FailureThresholdApplied bool   `json:"failureThresholdApplied"`
+	AllowedFailures         int    `json:"allowedFailures"`
+}
+
+// brunoExecuteResults is written to the workspace to allow following stages to act on the outcome of the run.
+// The counts and failed requests are only available with a JSON report.
+type brunoExecuteResults struct {
+	ExitCode       int      `json:"exitCode"`
+	Total          *int     `json:"total,omitempty"`
+	Failed         *int     `json:"failed,omitempty"`
+	FailedRequests []string `json:"failedRequests,omitempty"`
+}
+
+func writeBrunoResults(results *brunoRunResults, utils brunoExecuteUtils) error {
+	executeResults := brunoExecuteResults{ExitCode: results.exitCode}
+	if results.hasReport {
+		executeResults.Total = &results.metrics.Requests
+		executeResults.Failed = &results.metrics.FailedRequests
+		executeResults.FailedRequests = results.failedRequests
+	}
+	content, err := json.Marshal(executeResults)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize Bruno results")
+	}
+	if err := utils.FileWrite(brunoResultsFile, content, 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write Bruno results to '%v'", brunoResultsFile)
+	}
+	return nil
+}
+
+// brunoFailureCategory distinguishes failed tests from connectivity problems based on the cause of the failures in the JSON report.
+// ErrorUndefined is returned if the cause cannot be determined, e.g. without a JSON report.
+func brunoFailureCategory(cause string) log.ErrorCategory {
+	switch cause {
+	case bruno.FailureCauseTest:
+		return log.ErrorTest
+	case bruno.FailureCauseInfrastructure:
+		return log.ErrorInfrastructure
+	default:
+		return log.ErrorUndefined
+	}
+}
+
+// brunoExitCode returns the exit code of the failed Bruno CLI, which is unknown e.g. if it was terminated after a timeout
+func brunoExitCode(utils brunoExecuteUtils) int {
+	if exitCode := utils.GetExitCode(); exitCode != 0 {
+		return exitCode
+	}
+	return 1
+}
+
+func writeBrunoSummary(metrics bruno.Metrics, runErr error, commonPipelineEnvironment *brunoExecuteCommonPipelineEnvironment) error {
+	summary := brunoSummary{
+		Status:          "passed",
+		Total:           metrics.Requests,
+		Failed:          metrics.FailedRequests,
+		DurationMs:      metrics.DurationMs,
+		AllowedFailures: metrics.AllowedFailures,
+		// the step has no failure threshold, the failures of allowedFailures are reported separately
+		FailureThresholdApplied: false,
+	}
+	if runErr != nil || metrics.FailedRequests > 0 {
+		summary.Status = "failed"
+	}
+	content, err := json.Marshal(summary)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize Bruno summary")
+	}
+	commonPipelineEnvironment.custom.brunoSummary = string(content)
+	return nil
+}
+
+// writeBrunoAllureResult converts a result of the JSON report into an Allure result with the given index,
+// which keeps the results unique across several collections
+func writeBrunoAllureResult(config *brunoExecuteOptions, result bruno.Result, index int, utils brunoExecuteUtils) error {
+	result.Request.URL = bruno.SanitizeURL(result.Request.URL, config.MaskURLQueryParams)
+	allure := bruno.NewAllureResult(result, index)
+	content, err := json.MarshalIndent(allure, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "failed to serialize Allure result of '%v'", result.Name)
+	}
+	if err := utils.FileWrite(filepath.Join(config.AllureOutputDir, allure.UUID+"-result.json"), content, 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write Allure result of '%v'", result.Name)
+	}
+	for _, attachment := range allure.Attachments {
+		if err := utils.FileWrite(filepath.Join(config.AllureOutputDir, attachment.Source), []byte(result.FailureDetails()), 0o644); err != nil {
+			return errors.Wrapf(err, "failed to write Allure attachment of '%v'", result.Name)
+		}
+	}
+	return nil
+}
+
+// applyBrunoSpecFile merges the options of the JSON or YAML specFile into the config.
+// Options which differ from their default value are considered to be set explicitly and take precedence over the spec file.
+func applyBrunoSpecFile(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
+	content, err := utils.FileRead(config.SpecFile)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return errors.Wrapf(err, "failed to read specFile '%v'", config.SpecFile)
+	}
+	// YAML is a superset of JSON, so both are converted the same way
+	specJSON, err := yaml.YAMLToJSON(content)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return errors.Wrapf(err, "failed to parse specFile '%v'", config.SpecFile)
+	}
+	// decode into the options first to reject unknown options and values of the wrong type
+	decoder := json.NewDecoder(bytes.NewReader(specJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&brunoExecuteOptions{}); err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return errors.Wrapf(err, "failed to parse specFile '%v'", config.SpecFile)
+	}
+	spec := map[string]interface{}{}
+	if err := json.Unmarshal(specJSON, &spec); err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return errors.Wrapf(err, "failed to parse specFile '%v', the root element must be an object", config.SpecFile)
+	}
+
+	explicit := map[string]interface{}{}
+	if err := brunoJSONRoundTrip(config, &explicit); err != nil {
+		return err
+	}
+	defaults := map[string]interface{}{}
+	for _, param := range brunoExecuteMetadata().Spec.Inputs.Parameters {
+		var value interface{}
+		if err := brunoJSONRoundTrip(param.Default, &value); err != nil {
+			return err
+		}
+		defaults[param.Name] = value
+	}
+	for name, value := range explicit {
+		if _, inSpec := spec[name]; inSpec && reflect.DeepEqual(value, defaults[name]) {
+			continue
+		}
+		spec[name] = value
+	}
+
+	merged := brunoExecuteOptions{}
+	if err := brunoJSONRoundTrip(spec, &merged); err != nil {
+		return err
+	}
+	*config = merged
+	return nil
+}
+
+// brunoJSONRoundTrip converts a value to another type with the same JSON representation
+func brunoJSONRoundTrip(value, target interface{}) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal options")
+	}
+	return errors.Wrap(json.Unmarshal(content, target), "failed to unmarshal options")
+}
+
+// readBrunoEnvVarsFile reads the KEY=VALUE pairs of a .env style file, blank lines and comments starting with # are ignored
+func readBrunoEnvVarsFile(envVarsFile string, utils brunoExecuteUtils) ([]string, error) {
+	content, err := utils.FileRead(envVarsFile)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return nil, errors.Wrapf(err, "failed to read envVarsFile '%v'", envVarsFile)
+	}
+	envVars := []string{}
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.Contains(line, "=") {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			// the line itself is not part of the error as it might contain a secret
+			return nil, fmt.Errorf("line %v of envVarsFile '%v' is not a KEY=VALUE pair", i+1, envVarsFile)
+		}
+		envVars = append(envVars, line)
+	}
+	return envVars, nil
+}
+
+// brunoEffectiveConfig returns the options as JSON for logging, with the values of envVars and extraFlags masked
+// and credentials removed from URLs
+func brunoEffectiveConfig(config *brunoExecuteOptions) string {
+	effective := *config
+	effective.EnvVars = maskBrunoValues(config.EnvVars)
+	effective.ExtraFlags = maskBrunoValues(config.ExtraFlags)
+	effective.CollectionConfigs = nil
+	for _, collectionConfig := range config.CollectionConfigs {
+		masked := map[string]interface{}{}
+		for key, value := range collectionConfig {
+			masked[key] = value
+		}
+		if envVars, ok := collectionConfig["envVars"].([]interface{}); ok {
+			maskedEnvVars := []interface{}{}
+			for _, envVar := range envVars {
+				maskedEnvVars = append(maskedEnvVars, maskBrunoValues([]string{fmt.Sprint(envVar)})[0])
+			}
+			masked["envVars"] = maskedEnvVars
+		}
+		effective.CollectionConfigs = append(effective.CollectionConfigs, masked)
+	}
+	for _, rawURL := range []*string{&effective.CollectionGitURL, &effective.NpmRegistry, &effective.PreflightURL, &effective.HttpProxy, &effective.HttpsProxy, &effective.ResultsWebhookURL} {
+		*rawURL = bruno.SanitizeURL(*rawURL, config.MaskURLQueryParams)
+	}
+	effective.ResultsWebhookURL = maskBrunoURLPath(effective.ResultsWebhookURL)
+	content, err := json.Marshal(effective)
+	if err != nil {
+		return fmt.Sprintf("failed to serialize the configuration: %v", err)
+	}
+	return string(content)
+}
+
+// maskBrunoURLPath replaces the path and query of a URL, since webhook URLs commonly carry their token there
+func maskBrunoURLPath(rawURL string) string {
+	if rawURL == "" {
+		return rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "****"
+	}
+	masked := parsed.Scheme + "://" + parsed.Host
+	if (parsed.Path != "" && parsed.Path != "/") || parsed.RawQuery != "" {
+		masked += "/****"
+	}
+	return masked
+}
+
+// maskBrunoValues replaces the values of NAME=VALUE entries, entries without value are kept
+func maskBrunoValues(entries []string) []string {
+	masked := []string{}
+	for _, entry := range entries {
+		if name, value, found := strings.Cut(entry, "="); found && value != "" {
+			entry = name + "=****"
+		}
+		masked = append(masked, entry)
+	}
+	return masked
+}
+
+// brunoPrefixedEnvVars returns the environment variables starting with the prefix, with the prefix removed from their names.
+// Variables which are already part of envVars are skipped, so that the explicit value is used.
+func brunoPrefixedEnvVars(prefix string, envVars, environ []string) []string {
+	explicit := map[string]bool{}
+	for _, envVar := range envVars {
+		name, _, _ := strings.Cut(envVar, "=")
+		explicit[name] = true
+	}
+	prefixed := []string{}
+	for _, variable := range environ {
+		name, value, found := strings.Cut(variable, "=")
+		if !found || !strings.HasPrefix(name, prefix) || name == prefix {
+			continue
+		}
+		name = strings.TrimPrefix(name, prefix)
+		if explicit[name] {
+			log.Entry().Infof("environment variable '%v' is ignored, envVars contains '%v' already", prefix+name, name)
+			continue
+		}
+		prefixed = append(prefixed, name+"="+value)
+	}
+	sort.Strings(prefixed)
+	return prefixed
+}
+
+// brunoEnvVarValues returns the non-empty values of envVars, the values may contain '=' themselves
+func brunoEnvVarValues(config *brunoExecuteOptions) []string {
+	values := []string{}
+	for _, envVar := range config.EnvVars {
+		if _, value, found := strings.Cut(envVar, "="); found && value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func checkCleanBrunoCollection(collection string, utils brunoExecuteUtils) error {
+	var status bytes.Buffer
+	utils.Stdout(&status)
+	defer utils.Stdout(log.Writer())
+
+	err := utils.RunExecutable("git", "status", "--porcelain", "--", collection)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		return errors.Wrapf(err, "failed to check git status of Bruno collection '%v'", collection)
+	}
+	if changes := strings.TrimSpace(status.String()); changes != "" {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("the Bruno collection '%v' contains uncommitted changes:\n%v", collection, changes)
+	}
+	return nil
+}
+
+// logVersionsBruno logs the versions of Node.js and the package manager, which also verifies that their executables can be run
+func logVersionsBruno(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
+	retries := config.VersionCheckRetries
+	if retries > brunoVersionCheckMaxRetries {
+		log.Entry().Warnf("versionCheckRetries %v exceeds the maximum, using %v retries", retries, brunoVersionCheckMaxRetries)
+		retries = brunoVersionCheckMaxRetries
+	}
+	var nodeVersionOutput bytes.Buffer
+	if config.MinNodeVersion != "" {
+		utils.Stdout(io.MultiWriter(log.Writer(), &nodeVersionOutput))
+	}
+	err := runVersionCheckBruno(brunoNodeBinary(config), retries, utils)
+	if config.MinNodeVersion != "" {
+		utils.Stdout(log.Writer())
+	}
+	if err != nil {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		return errors.Wrap(err, "error logging node version")
+	}
+	if config.MinNodeVersion != "" {
+		if err := checkBrunoNodeVersion(config.MinNodeVersion, nodeVersionOutput.String()); err != nil {
+			return err
+		}
+	}
+	packageManager := brunoPackageManager(config)
+	packageManagerBinary := packageManager
+	if packageManager == "npm" {
+		packageManagerBinary = brunoNpmBinary(config)
+	}
+	err = runVersionCheckBruno(packageManagerBinary, retries, utils)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		return errors.Wrapf(err, "error logging %v version", packageManager)
+	}
+	return nil
+}
+
+func runVersionCheckBruno(executable string, retries int, utils brunoExecuteUtils) error {
+	delay := brunoVersionCheckRetryDelay
+	for attempt := 0; ; attempt++ {
+		err := utils.RunExecutable(executable, "--version")
+		if err == nil || attempt >= retries {
+			return err
+		}
+		log.Entry().WithError(err).Warnf("logging %v version failed, retrying in %v", executable, delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// checkBrunoNodeVersion compares the output of node --version, e.g. v20.11.0, with minNodeVersion
+func checkBrunoNodeVersion(minNodeVersion, versionOutput string) error {
+	minimum, err := parseBrunoVersionNumbers(minNodeVersion)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("invalid minNodeVersion '%v', a version like '18' or '20.11.0' is required", minNodeVersion)
+	}
+	// with retries, the output of the successful attempt comes last
+	fields := strings.Fields(versionOutput)
+	if len(fields) == 0 {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		return errors.New("could not determine the node version, the output of node --version is empty")
+	}
+	nodeVersion := fields[len(fields)-1]
+	installed, err := parseBrunoVersionNumbers(nodeVersion)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		return fmt.Errorf("could not determine the node version from '%v'", nodeVersion)
+	}
+	for i, number := range minimum {
+		current := 0
+		if i < len(installed) {
+			current = installed[i]
+		}
+		if current > number {
+			break
+		}
+		if current < number {
+			log.SetErrorCategory(log.ErrorInfrastructure)
+			return fmt.Errorf("node version %v is lower than the required minNodeVersion %v, use an image with a newer Node.js or set nodeBinary", nodeVersion, minNodeVersion)
+		}
+	}
+	log.Entry().Debugf("node version %v satisfies minNodeVersion %v", nodeVersion, minNodeVersion)
+	return nil
+}
+
+// parseBrunoVersionNumbers returns the numeric parts of a version like v20.11.0, a pre-release suffix is ignored
+func parseBrunoVersionNumbers(version string) ([]int, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "-")
+	numbers := []int{}
+	for _, part := range strings.Split(version, ".") {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("invalid version '%v'", version)
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+// isBrunoInstalled checks with skipInstallIfPresent whether the Bruno CLI already exists at the path it is executed from
+func isBrunoInstalled(config *brunoExecuteOptions, utils brunoExecuteUtils) (bool, error) {
+	if !config.SkipInstallIfPresent {
+		return false, nil
+	}
+	if brunoLookupInPath(config) {
+		log.Entry().Info("skipInstallIfPresent requires npmGlobalPrefix if manageInstallPrefix is disabled, installing the Bruno CLI")
+		return false, nil
+	}
+	brunoPath := brunoExecutablePath(config, utils)
+	exists, err := utils.FileExists(brunoPath)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		return false, errors.Wrapf(err, "failed to check whether the Bruno CLI exists at '%v'", brunoPath)
+	}
+	if exists {
+		log.Entry().Infof("the Bruno CLI is already present at '%v', skipping the installation", brunoPath)
+	}
+	return exists, nil
+}
+
+func installBruno(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
+	packageManager := brunoPackageManager(config)
+	if packageManager == "pnpm" {
+		// pnpm installs global binaries to PNPM_HOME, which it requires to be part of the PATH
+		binDir := filepath.Join(expandNpmGlobalPrefix(npmGlobalPrefix(config), utils), "bin")
+		utils.AppendEnv([]string{"PNPM_HOME=" + binDir, "PATH=" + binDir + string(os.PathListSeparator) + utils.Getenv("PATH")})
+	}
+
+	maxInstalledPackages := config.MaxInstalledPackages
+	if maxInstalledPackages > 0 && packageManager != "npm" {
+		log.Entry().Warnf("maxInstalledPackages is only supported with npm, skipping the check for %v", packageManager)
+		maxInstalledPackages = 0
+	}
+	var installOutput bytes.Buffer
+	if maxInstalledPackages > 0 {
+		utils.Stdout(io.MultiWriter(log.Writer(), &installOutput))
+		defer utils.Stdout(log.Writer())
+	}
+
+	delay := brunoInstallRetryDelay
+	for attempt := 0; ; attempt++ {
+		installCommandTokens, err := resolveBrunoInstallCommand(config, utils)
+		if err != nil {
+			return err
+		}
+		installOutput.Reset()
+		log.Entry().Infof("installing Bruno CLI (attempt %v of %v)", attempt+1, config.InstallRetries+1)
+		err = utils.RunExecutable(installCommandTokens[0], installCommandTokens[1:]...)
+		if err == nil {
+			break
+		}
+		if attempt >= config.InstallRetries {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return errors.Wrap(err, "error installing Bruno CLI")
+		}
+		log.Entry().WithError(err).Warnf("installing Bruno CLI failed, retrying in %v", delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	if maxInstalledPackages > 0 {
+		addedPackages := parseNpmAddedPackages(installOutput.String())
+		if addedPackages > maxInstalledPackages {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return fmt.Errorf("the Bruno CLI installation added %v packages, which exceeds the maximum of %v", addedPackages, maxInstalledPackages)
+		}
+		log.Entry().Infof("the Bruno CLI installation added %v packages", addedPackages)
+	}
+	return nil
+}
+
+// uninstallBruno removes the Bruno CLI from the global prefix again, failures are only logged
+func uninstallBruno(config *brunoExecuteOptions, utils brunoExecuteUtils) {
+	var uninstallCommandTokens []string
+	switch brunoPackageManager(config) {
+	case "yarn":
+		uninstallCommandTokens = []string{"yarn", "global", "remove", brunoCliPackage, "--prefix", expandNpmGlobalPrefix(npmGlobalPrefix(config), utils)}
+	case "pnpm":
+		uninstallCommandTokens = []string{"pnpm", "remove", "--global", brunoCliPackage}
+	default:
+		uninstallCommandTokens = []string{brunoNpmBinary(config), "uninstall", brunoCliPackage, "--global"}
+		if config.ManageInstallPrefix {
+			uninstallCommandTokens = append(uninstallCommandTokens, "--prefix="+npmGlobalPrefix(config))
+		}
+	}
+	log.Entry().Info("uninstalling Bruno CLI")
+	if err := utils.RunExecutable(uninstallCommandTokens[0], uninstallCommandTokens[1:]...); err != nil {
+		log.Entry().WithError(err).Warn("failed to uninstall Bruno CLI")
+	}
+}
+
+// resolveBrunoInstallCommand returns the tokens of the global install command of the package manager.
+// For npm, brunoInstallCommand is used and the Bruno CLI package is pinned to brunoVersion if set.
+// All package managers install from npmRegistry if set, yarn and pnpm are configured to install the binaries to the bin directory of npmGlobalPrefix like npm does.
+func resolveBrunoInstallCommand(config *brunoExecuteOptions, utils brunoExecuteUtils) ([]string, error) {
+	if config.BrunoVersion != "" && !brunoVersionRegex.MatchString(config.BrunoVersion) {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return nil, fmt.Errorf("invalid brunoVersion '%v', only letters, digits and the characters '.+-_^~' are allowed", config.BrunoVersion)
+	}
+	if config.NpmRegistry != "" {
+		if registry, err := url.Parse(config.NpmRegistry); err != nil || (registry.Scheme != "http" && registry.Scheme != "https") || registry.Host == "" {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, fmt.Errorf("invalid npmRegistry '%v', an http or https URL is required", config.NpmRegistry)
+		}
+	}
+	packageSpec := brunoCliPackage
+	if config.BrunoVersion != "" {
+		packageSpec += "@" + config.BrunoVersion
 	}
-	// Reroute command output to logging framework
-	utils.Stdout(log.Writer())
-	utils.Stderr(log.Writer())
-	return &utils
-}
 
-func brunoExecute(config brunoExecuteOptions, _ *telemetry.CustomData, influx *brunoExecuteInflux) {
-	utils := newBrunoExecuteUtils()
+	var installCommandTokens []string
+	switch brunoPackageManager(config) {
+	case "yarn":
+		installCommandTokens = []string{"yarn", "global", "add", packageSpec, "--prefix", expandNpmGlobalPrefix(npmGlobalPrefix(config), utils)}
+	case "pnpm":
+		installCommandTokens = []string{"pnpm", "add", "--global", packageSpec}
+	default:
+		installCommandTokens = strings.Split(config.BrunoInstallCommand, " ")
+		if installCommandTokens[0] == "npm" {
+			installCommandTokens[0] = brunoNpmBinary(config)
+		}
+		if config.BrunoVersion != "" {
+			pinned := false
+			for i, token := range installCommandTokens {
+				if token == brunoCliPackage || strings.HasPrefix(token, brunoCliPackage+"@") {
+					installCommandTokens[i] = packageSpec
+					pinned = true
+				}
+			}
+			if !pinned {
+				installCommandTokens = append(installCommandTokens, packageSpec)
+			}
+		}
+		if config.ManageInstallPrefix {
+			installCommandTokens = append(installCommandTokens, "--prefix="+npmGlobalPrefix(config))
+		}
+	}
 
-	influx.step_data.fields.bruno = false
-	err := runBrunoExecute(&config, utils)
-	if err != nil {
-		log.Entry().WithError(err).Fatal("step execution failed")
+	if config.NpmRegistry != "" {
+		installCommandTokens = append(installCommandTokens, "--registry="+config.NpmRegistry)
 	}
-	influx.step_data.fields.bruno = true
+	return installCommandTokens, nil
 }
 
-func runBrunoExecute(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
-	err := logVersionsBruno(utils)
-	if err != nil {
-		return err
+func brunoPackageManager(config *brunoExecuteOptions) string {
+	if config.PackageManager == "" {
+		return "npm"
 	}
+	return config.PackageManager
+}
 
-	err = installBruno(config.BrunoInstallCommand, utils)
-	if err != nil {
-		return err
+func brunoNodeBinary(config *brunoExecuteOptions) string {
+	if config.NodeBinary == "" {
+		return "node"
 	}
+	return config.NodeBinary
+}
 
-	runOptions, err := resolveRunOptions(config)
-	if err != nil {
-		return err
+func brunoSubcommand(config *brunoExecuteOptions) string {
+	if config.BrunoSubcommand == "" {
+		return "run"
 	}
+	return config.BrunoSubcommand
+}
 
-	// Build additional options from config parameters
-	additionalOptions := buildBrunoOptions(config)
-	runOptions = append(runOptions, additionalOptions...)
+func brunoNpmBinary(config *brunoExecuteOptions) string {
+	if config.NpmBinary == "" {
+		return "npm"
+	}
+	return config.NpmBinary
+}
 
-	brunoPath := filepath.Join(utils.Getenv("HOME"), "/.npm-global/bin/bru")
-	err = utils.RunExecutable(brunoPath, runOptions...)
-	if err != nil {
-		if !config.FailOnError {
-			log.Entry().WithError(err).Warn("Bruno tests failed, but failOnError is set to false")
-			return nil
-		}
-		return errors.Wrap(err, "The execution of the Bruno tests failed, see the log for details.")
+// brunoExecutablePath returns the path of the Bruno CLI within the global prefix, by default within its bin directory which is used by npm, yarn and pnpm.
+// If the prefix is neither managed by the step nor configured, the Bruno CLI is looked up in the PATH.
+func brunoExecutablePath(config *brunoExecuteOptions, utils brunoExecuteUtils) string {
+	if brunoLookupInPath(config) {
+		return "bru"
 	}
+	relPath := config.BruBinaryRelPath
+	if relPath == "" {
+		relPath = "bin/bru"
+	}
+	return filepath.Join(expandNpmGlobalPrefix(npmGlobalPrefix(config), utils), filepath.FromSlash(relPath))
+}
 
-	return nil
+// checkBrunoRequestTimeout disables requestTimeoutMs with a warning if the help of the installed Bruno CLI does not list --timeout
+func checkBrunoRequestTimeout(config *brunoExecuteOptions, brunoPath string, utils brunoExecuteUtils) {
+	var help bytes.Buffer
+	utils.Stdout(&help)
+	err := utils.RunExecutable(brunoPath, brunoSubcommand(config), "--help")
+	utils.Stdout(log.Writer())
+	if err != nil || !strings.Contains(help.String(), "--timeout") {
+		log.Entry().Warn("requestTimeoutMs is ignored, the installed Bruno CLI does not support --timeout")
+		config.RequestTimeoutMs = 0
+	}
 }
 
-func logVersionsBruno(utils brunoExecuteUtils) error {
-	err := utils.RunExecutable("node", "--version")
-	if err != nil {
-		log.SetErrorCategory(log.ErrorInfrastructure)
-		return errors.Wrap(err, "error logging node version")
+// checkBrunoExecutable verifies that the installation placed the Bruno CLI where it is executed from
+func checkBrunoExecutable(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
+	if brunoLookupInPath(config) {
+		return nil
 	}
-	err = utils.RunExecutable("npm", "--version")
+	brunoPath := brunoExecutablePath(config, utils)
+	exists, err := utils.FileExists(brunoPath)
 	if err != nil {
 		log.SetErrorCategory(log.ErrorInfrastructure)
-		return errors.Wrap(err, "error logging npm version")
+		return errors.Wrapf(err, "failed to check whether the Bruno CLI exists at '%v'", brunoPath)
+	}
+	if !exists {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("the Bruno CLI does not exist at '%v' after the installation, set bruBinaryRelPath to its path relative to the npm global prefix", brunoPath)
 	}
 	return nil
 }
 
-func installBruno(brunoInstallCommand string, utils brunoExecuteUtils) error {
-	installCommandTokens := strings.Split(brunoInstallCommand, " ")
-	installCommandTokens = append(installCommandTokens, "--prefix=~/.npm-global")
-	err := utils.RunExecutable(installCommandTokens[0], installCommandTokens[1:]...)
-	if err != nil {
-		log.SetErrorCategory(log.ErrorConfiguration)
-		return errors.Wrap(err, "error installing Bruno CLI")
+// brunoLookupInPath reports whether the npm install command uses its own prefix, which is unknown to the step
+func brunoLookupInPath(config *brunoExecuteOptions) bool {
+	return !config.ManageInstallPrefix && config.NpmGlobalPrefix == "" && brunoPackageManager(config) == "npm"
+}
+
+func npmGlobalPrefix(config *brunoExecuteOptions) string {
+	if config.NpmGlobalPrefix == "" {
+		return defaultNpmGlobalPrefix
 	}
-	return nil
+	return config.NpmGlobalPrefix
 }
 
-func buildBrunoOptions(config *brunoExecuteOptions) []string {
-	options := []string{}
+// ensureWritableNpmGlobalPrefix verifies that the Bruno CLI can be installed to the npm global prefix.
+// If the prefix is not writable, a temporary prefix is used with fallbackToTempPrefix, otherwise the step fails.
+func ensureWritableNpmGlobalPrefix(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
+	if brunoLookupInPath(config) {
+		return nil
+	}
+	prefix := expandNpmGlobalPrefix(npmGlobalPrefix(config), utils)
+	err := checkWritableDir(prefix, utils)
+	if err == nil {
+		return nil
+	}
+	if !config.FallbackToTempPrefix {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		return errors.Wrapf(err, "the npm global prefix '%v' is not writable, set npmGlobalPrefix to a writable directory or enable fallbackToTempPrefix", prefix)
+	}
+	tempPrefix, tempErr := utils.TempDir("", "bruno-npm-global")
+	if tempErr != nil {
+		log.SetErrorCategory(log.ErrorInfrastructure)
+		return errors.Wrapf(tempErr, "the npm global prefix '%v' is not writable and no temporary prefix could be created", prefix)
+	}
+	log.Entry().WithError(err).Warnf("the npm global prefix '%v' is not writable, installing the Bruno CLI to '%v' instead", prefix, tempPrefix)
+	config.NpmGlobalPrefix = tempPrefix
+	return nil
+}
 
-	// Environment options
-	if config.BrunoEnvironment != "" {
-		options = append(options, "--env", config.BrunoEnvironment)
+func checkWritableDir(dir string, utils brunoExecuteUtils) error {
+	if err := utils.MkdirAll(dir, 0o755); err != nil {
+		return err
 	}
-	if config.BrunoGlobalEnv != "" {
-		options = append(options, "--global-env", config.BrunoGlobalEnv)
+	probe := filepath.Join(dir, ".piper-write-check")
+	if err := utils.FileWrite(probe, []byte{}, 0o644); err != nil {
+		return err
 	}
-	if config.EnvFile != "" {
-		options = append(options, "--env-file", config.EnvFile)
+	return utils.FileRemove(probe)
+}
+
+// checkBrunoHome fails if the npm global prefix refers to the home directory, but HOME is not set as in some minimal container images.
+// The prefix would otherwise be expanded to the root directory.
+func checkBrunoHome(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
+	if brunoLookupInPath(config) {
+		return nil
 	}
-	for _, envVar := range config.EnvVars {
-		options = append(options, "--env-var", envVar)
+	prefix := npmGlobalPrefix(config)
+	if (prefix == "~" || strings.HasPrefix(prefix, "~/")) && utils.Getenv("HOME") == "" {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return fmt.Errorf("the environment variable HOME is not set, which is required for the npm global prefix '%v'. Set HOME or set npmGlobalPrefix to an absolute path", prefix)
+	}
+	return nil
+}
+
+// expandNpmGlobalPrefix replaces a leading ~ of the prefix with the home directory
+func expandNpmGlobalPrefix(prefix string, utils brunoExecuteUtils) string {
+	if prefix == "~" || strings.HasPrefix(prefix, "~/") {
+		return filepath.Join(utils.Getenv("HOME"), prefix[1:])
 	}
+	return prefix
+}
 
-	// Sandbox mode
-	if config.SandboxMode != "" {
-		options = append(options, "--sandbox", config.SandboxMode)
+// parseNpmAddedPackages extracts the number of added packages from the npm install summary,
+// e.g. "added 87 packages in 3s". An install without such a line did not add any packages.
+func parseNpmAddedPackages(summary string) int {
+	matches := npmAddedPackagesRegex.FindStringSubmatch(summary)
+	if matches == nil {
+		return 0
 	}
+	addedPackages, _ := strconv.Atoi(matches[1])
+	return addedPackages
+}
+
+func buildBrunoOptions(config *brunoExecuteOptions) []string {
+	options := buildBrunoEnvironmentOptions(config)
 
 	// Execution options
 	if config.Recursive {
 		options = append(options, "-r")
 	}
-	if config.Bail {
+	if config.BailAfter > 1 {
+		// bru run --bail takes no threshold, stopping too early is preferred over running all requests
+		log.Entry().Warnf("the Bruno CLI does not support stopping after %v failures, falling back to --bail which stops after the first failure", config.BailAfter)
+		options = append(options, "--bail")
+	} else if config.BailAfter == 1 || config.Bail {
 		options = append(options, "--bail")
 	}
 	if config.Parallel {
@@ -155,9 +2151,22 @@ func buildBrunoOptions(config *brunoExecuteOptions) []string {
 	if config.Insecure {
 		options = append(options, "--insecure")
 	}
+	if config.DisableCookies {
+		options = append(options, "--disable-cookies")
+	}
+	if config.BrunoNoProxy {
+		options = append(options, "--noproxy")
+	}
 	if config.Delay > 0 {
 		options = append(options, "--delay", strconv.Itoa(config.Delay))
 	}
+	if config.RequestTimeoutMs > 0 {
+		options = append(options, "--timeout", strconv.Itoa(config.RequestTimeoutMs))
+	}
+	// the debug log level of the step implies verbose output of Bruno CLI
+	if config.Verbose || log.IsVerbose() {
+		options = append(options, "--verbose")
+	}
 
 	// Data-driven testing options
 	if config.CsvFilePath != "" {
@@ -172,6 +2181,10 @@ func buildBrunoOptions(config *brunoExecuteOptions) []string {
 
 	// Tag filtering options
 	if config.Tags != "" {
+		if config.TagsMatchMode == "any" && strings.Contains(config.Tags, ",") {
+			// bru run --tags only matches requests with all of the tags
+			log.Entry().Warnf("the Bruno CLI does not support running requests with any of the tags %v, only requests with all of them are run", config.Tags)
+		}
 		options = append(options, "--tags", config.Tags)
 	}
 	if config.ExcludeTags != "" {
@@ -191,13 +2204,249 @@ func buildBrunoOptions(config *brunoExecuteOptions) []string {
 	if config.ReporterSkipAllHeaders {
 		options = append(options, "--reporter-skip-all-headers")
 	}
-	for _, header := range config.ReporterSkipHeaders {
+	for _, header := range brunoReporterSkipHeaders(config) {
 		options = append(options, "--reporter-skip-headers", header)
 	}
 
+	// Extra flags are passed as single arguments without any shell splitting
+	for _, flag := range config.ExtraFlags {
+		log.Entry().Infof("passing extra flag '%v' to Bruno CLI", flag)
+		options = append(options, flag)
+	}
+
+	return options
+}
+
+// buildBrunoEnvironmentOptions builds the options required to send requests in the configured environment
+func buildBrunoEnvironmentOptions(config *brunoExecuteOptions) []string {
+	options := []string{}
+
+	// Environment options
+	if config.BrunoEnvironment != "" {
+		options = append(options, "--env", config.BrunoEnvironment)
+	}
+	if config.BrunoGlobalEnv != "" {
+		options = append(options, "--global-env", config.BrunoGlobalEnv)
+	}
+	if config.EnvFile != "" {
+		options = append(options, "--env-file", config.EnvFile)
+	}
+	for _, envVar := range config.EnvVars {
+		options = append(options, "--env-var", envVar)
+	}
+	if config.ClientCertConfig != "" {
+		options = append(options, "--client-cert-config", config.ClientCertConfig)
+	}
+	if config.CaCert != "" && !config.Insecure {
+		options = append(options, "--cacert", config.CaCert)
+	}
+
+	// Sandbox mode
+	if config.SandboxMode != "" {
+		options = append(options, "--sandbox", config.SandboxMode)
+	}
+
 	return options
 }
 
+// brunoReporterSkipHeaders merges reporterSkipHeaders and reporterSkipResponseHeaders, since bru run --reporter-skip-headers
+// applies to request and response headers alike. Header names are case-insensitive, duplicates are skipped.
+func brunoReporterSkipHeaders(config *brunoExecuteOptions) []string {
+	headers := []string{}
+	seen := map[string]bool{}
+	for _, header := range append(slices.Clone(config.ReporterSkipHeaders), config.ReporterSkipResponseHeaders...) {
+		if seen[strings.ToLower(header)] {
+			continue
+		}
+		seen[strings.ToLower(header)] = true
+		headers = append(headers, header)
+	}
+	return headers
+}
+
+// brunoReporterNames maps the reporter options of Bruno CLI to the names of the reports in the pipeline
+var brunoReporterNames = map[string]string{
+	"--reporter-junit": "Bruno JUnit report",
+	"--reporter-html":  "Bruno HTML report",
+	"--reporter-json":  "Bruno JSON report",
+}
+
+// collectBrunoReports returns the reports written by the Bruno CLI according to the final run options.
+// Reports which do not exist, e.g. because the run crashed early, are skipped with a warning.
+func collectBrunoReports(config *brunoExecuteOptions, runOptions []string, utils brunoExecuteUtils) []piperutils.Path {
+	reports := []piperutils.Path{}
+	for i, option := range runOptions {
+		reporter, target, found := strings.Cut(option, "=")
+		if !found {
+			if i+1 >= len(runOptions) {
+				break
+			}
+			target = runOptions[i+1]
+		}
+		name, ok := brunoReporterNames[reporter]
+		if !ok {
+			continue
+		}
+		target = brunoWorkingDirPath(config, target)
+		if exists, _ := utils.FileExists(target); !exists {
+			log.Entry().Warnf("the %v '%v' does not exist and is not archived", name, target)
+			continue
+		}
+		reports = append(reports, piperutils.Path{Name: name, Target: target})
+	}
+	return reports
+}
+
+// mergeBrunoJunitReports combines the test suites of the JUnit reports of all collections into a single report.
+// Reports which cannot be read are skipped with a warning. The merged report is returned for archiving.
+func mergeBrunoJunitReports(config *brunoExecuteOptions, reports []piperutils.Path, utils brunoExecuteUtils) ([]piperutils.Path, error) {
+	suites := []bruno.JUnitTestSuite{}
+	for _, report := range reports {
+		if report.Name != brunoReporterNames["--reporter-junit"] {
+			continue
+		}
+		content, err := utils.FileRead(report.Target)
+		if err != nil {
+			log.Entry().WithError(err).Warnf("failed to read the JUnit report '%v', skipping it in the merged report", report.Target)
+			continue
+		}
+		reportSuites, err := bruno.ParseJUnitSuites(content)
+		if err != nil {
+			log.Entry().WithError(err).Warnf("failed to parse the JUnit report '%v', skipping it in the merged report", report.Target)
+			continue
+		}
+		suites = append(suites, reportSuites...)
+	}
+	if len(suites) == 0 {
+		log.Entry().Warn("no JUnit reports found, skipping the merged JUnit report")
+		return nil, nil
+	}
+
+	mergedPath := config.MergedJunitPath
+	if mergedPath == "" {
+		mergedPath = brunoMergedJunitFile
+	}
+	var merged bytes.Buffer
+	if err := bruno.WriteJUnit(&merged, bruno.MergeJUnitSuites(suites)); err != nil {
+		return nil, err
+	}
+	if err := utils.FileWrite(mergedPath, merged.Bytes(), 0o644); err != nil {
+		return nil, errors.Wrapf(err, "failed to write the merged JUnit report to '%v'", mergedPath)
+	}
+	log.Entry().Infof("merged %v JUnit test suites into '%v'", len(suites), mergedPath)
+	return []piperutils.Path{{Name: "Bruno merged JUnit report", Target: mergedPath}}, nil
+}
+
+// writeBrunoCrashJUnit writes a JUnit report with a failing test case if the Bruno CLI failed without writing the configured one,
+// e.g. because it crashed. Otherwise, CI systems would only report missing test results.
+func writeBrunoCrashJUnit(config *brunoExecuteOptions, runOptions []string, runErr error, utils brunoExecuteUtils) error {
+	target := brunoReporterTarget(runOptions, "--reporter-junit")
+	if target == "" {
+		return nil
+	}
+	path := brunoWorkingDirPath(config, target)
+	if exists, _ := utils.FileExists(path); exists {
+		return nil
+	}
+	suites, err := bruno.NewCrashJUnitSuites(config.BrunoCollection, runErr.Error())
+	if err != nil {
+		return err
+	}
+	var report bytes.Buffer
+	if err := bruno.WriteJUnit(&report, suites); err != nil {
+		return err
+	}
+	if err := utils.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrapf(err, "failed to create the directory of the JUnit report '%v'", target)
+	}
+	if err := utils.FileWrite(path, report.Bytes(), 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write the JUnit report '%v'", target)
+	}
+	log.Entry().Warnf("the Bruno CLI did not write the JUnit report '%v', writing a failing test case instead", target)
+	return nil
+}
+
+// checkBrunoReporterDirs creates the directories of the reports if needed and verifies that they are writable,
+// so that the results of a run are not lost because the Bruno CLI cannot write its reports
+func checkBrunoReporterDirs(config *brunoExecuteOptions, runOptions []string, utils brunoExecuteUtils) error {
+	checked := map[string]bool{}
+	for _, reporter := range []string{"--reporter-json", "--reporter-junit", "--reporter-html"} {
+		target := brunoReporterTarget(runOptions, reporter)
+		if target == "" {
+			continue
+		}
+		dir := filepath.Dir(brunoWorkingDirPath(config, target))
+		if checked[dir] {
+			continue
+		}
+		checked[dir] = true
+		if err := checkWritableDir(dir, utils); err != nil {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return errors.Wrapf(err, "the directory '%v' of the %v '%v' is not writable", dir, brunoReporterNames[reporter], target)
+		}
+	}
+	return nil
+}
+
+// brunoReporterTarget returns the path of a reporter within the run options, supporting both --reporter-x path and --reporter-x=path
+func brunoReporterTarget(runOptions []string, reporter string) string {
+	for i, option := range runOptions {
+		if target, found := strings.CutPrefix(option, reporter+"="); found {
+			return target
+		}
+		if option == reporter && i+1 < len(runOptions) {
+			return runOptions[i+1]
+		}
+	}
+	return ""
+}
+
+// resolveBrunoTestFiles replaces the collection within the run options by the test files relative to the collection,
+// so that only these are run. The files are appended if the collection is not part of the run options.
+func resolveBrunoTestFiles(config *brunoExecuteOptions, runOptions []string, utils brunoExecuteUtils) ([]string, error) {
+	collection := trimBrunoCollectionPath(config.BrunoCollection)
+	testFiles := []string{}
+	for _, testFile := range config.TestFiles {
+		path := filepath.Join(collection, testFile)
+		exists, err := utils.FileExists(brunoWorkingDirPath(config, path))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check the test file '%v'", path)
+		}
+		if !exists {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, fmt.Errorf("the test file '%v' does not exist in collection '%v'", testFile, config.BrunoCollection)
+		}
+		testFiles = append(testFiles, path)
+	}
+
+	index := slices.Index(runOptions, collection)
+	if index < 0 {
+		return append(runOptions, testFiles...), nil
+	}
+	return slices.Concat(runOptions[:index], testFiles, runOptions[index+1:]), nil
+}
+
+// resolveBrunoReporterPaths generates the paths of the reports not configured explicitly within reportsDirectory,
+// so that the following processing of the reports uses the generated paths as well
+func resolveBrunoReporterPaths(config *brunoExecuteOptions) {
+	if config.ReportsDirectory == "" {
+		return
+	}
+	reportName := "TEST-" + brunoCollectionDisplayName(config)
+	if suffix := brunoReportFilenameSuffix(config); suffix != "" {
+		reportName += "-" + suffix
+	}
+	if config.ReporterJunit == "" && !containsReporterJunit(config.RunOptions) {
+		config.ReporterJunit = filepath.Join(config.ReportsDirectory, reportName+".xml")
+	}
+	if config.ReporterHtml == "" && !containsReporterHtml(config.RunOptions) {
+		config.ReporterHtml = filepath.Join(config.ReportsDirectory, reportName+".html")
+	}
+	if config.ReporterJSON == "" {
+		config.ReporterJSON = filepath.Join(config.ReportsDirectory, reportName+".json")
+	}
+}
+
 func containsReporterJunit(runOptions []string) bool {
 	for _, opt := range runOptions {
 		if strings.Contains(opt, "--reporter-junit") {
@@ -218,7 +2467,103 @@ func containsReporterHtml(runOptions []string) bool {
 
 func resolveRunOptions(config *brunoExecuteOptions) ([]string, error) {
 	cmd := []string{}
-	collectionDisplayName := defineBrunoCollectionDisplayName(config.BrunoCollection)
+
+	runOptions := config.RunOptions
+	if len(runOptions) == 0 {
+		if !config.DefaultRunOptions {
+			log.SetErrorCategory(log.ErrorConfiguration)
+			return nil, errors.New("runOptions must not be empty, provide the Bruno CLI command (e.g. [run, '{{.BrunoCollection}}']) or enable defaultRunOptions")
+		}
+		log.Entry().Info("runOptions is empty, falling back to the default run options")
+		runOptions = []string{"{{.BrunoCollection}}"}
+	}
+	if subcommand := brunoSubcommand(config); runOptions[0] != subcommand {
+		runOptions = append([]string{subcommand}, runOptions...)
+	}
+
+	if err := validateBrunoTemplates(runOptions, "Bruno command"); err != nil {
+		return nil, err
+	}
+
+	suffix := brunoReportFilenameSuffix(config)
+	for _, runOption := range runOptions {
+		resolved, err := resolveBrunoTemplate(config, runOption, "Bruno command", nil)
+		if err != nil {
+			return nil, err
+		}
+		// report names like target/bruno/TEST-{{.CollectionDisplayName}}.xml
+		if suffix != "" && strings.Contains(runOption, ".CollectionDisplayName") && filepath.Ext(resolved) != "" {
+			resolved = insertBrunoReportSuffix(resolved, suffix)
+		}
+		cmd = append(cmd, resolved)
+	}
+
+	return cmd, nil
+}
+
+// resolveBrunoDataFilePaths resolves templates within csvFilePath and jsonFilePath like within runOptions
+func resolveBrunoDataFilePaths(config *brunoExecuteOptions) error {
+	var err error
+	if config.CsvFilePath, err = resolveBrunoTemplate(config, config.CsvFilePath, "csvFilePath", nil); err != nil {
+		return err
+	}
+	config.JSONFilePath, err = resolveBrunoTemplate(config, config.JSONFilePath, "jsonFilePath", nil)
+	return err
+}
+
+// resolveBrunoEnvVars resolves templates within the values of envVars like within runOptions.
+// In addition, the vault function reads a secret of the Vault integration, e.g. {{vault "team/bruno/apiKey"}}.
+// The resolved values are registered as secrets, since they commonly carry credentials as well.
+func resolveBrunoEnvVars(config *brunoExecuteOptions, utils brunoExecuteUtils) error {
+	funcs := template.FuncMap{"vault": brunoVaultSecret(utils)}
+	envVars := make([]string, 0, len(config.EnvVars))
+	for _, envVar := range config.EnvVars {
+		name, value, found := strings.Cut(envVar, "=")
+		if !found || !strings.Contains(value, "{{") {
+			envVars = append(envVars, envVar)
+			continue
+		}
+		resolved, err := resolveBrunoTemplate(config, value, fmt.Sprintf("envVars entry '%v'", name), funcs)
+		if err != nil {
+			return err
+		}
+		if resolved != "" {
+			log.RegisterSecret(resolved)
+		}
+		envVars = append(envVars, name+"="+resolved)
+	}
+	config.EnvVars = envVars
+	return nil
+}
+
+// brunoVaultSecret returns the vault template function, which reads the key of a Vault secret referenced as "path/key"
+func brunoVaultSecret(utils brunoExecuteUtils) func(string) (string, error) {
+	return func(reference string) (string, error) {
+		secretPath, key := path.Split(reference)
+		secretPath = strings.TrimSuffix(secretPath, "/")
+		if secretPath == "" || key == "" {
+			return "", fmt.Errorf("invalid Vault reference '%v', expected 'path/key'", reference)
+		}
+		client := utils.GetVaultClient()
+		if client == nil {
+			return "", fmt.Errorf("cannot resolve Vault reference '%v', Vault is not configured (vaultServerUrl and Vault credentials are required)", reference)
+		}
+		secret, err := client.GetKvSecret(secretPath)
+		if err != nil {
+			return "", errors.Wrapf(err, "could not read Vault secret '%v'", secretPath)
+		}
+		value, ok := secret[key]
+		if !ok {
+			return "", fmt.Errorf("the Vault secret '%v' does not contain the key '%v'", secretPath, key)
+		}
+		log.RegisterSecret(value)
+		return value, nil
+	}
+}
+
+// resolveBrunoTemplate renders a text/template with the step configuration, the collection, its display name, the getenv function and the given additional functions
+func resolveBrunoTemplate(config *brunoExecuteOptions, text, description string, funcs template.FuncMap) (string, error) {
+	brunoCollection := trimBrunoCollectionPath(config.BrunoCollection)
 
 	type TemplateConfig struct {
 		Config                interface{}
@@ -226,41 +2571,113 @@ func resolveRunOptions(config *brunoExecuteOptions) ([]string, error) {
 		BrunoCollection       string
 	}
 
-	for _, runOption := range config.RunOptions {
-		templ, err := template.New("template").Funcs(template.FuncMap{
-			"getenv": func(varName string) string {
-				return os.Getenv(varName)
-			},
-		}).Parse(runOption)
-		if err != nil {
-			log.SetErrorCategory(log.ErrorConfiguration)
-			return nil, errors.Wrap(err, "could not parse Bruno command template")
-		}
-		buf := new(bytes.Buffer)
-		err = templ.Execute(buf, TemplateConfig{
-			Config:                config,
-			CollectionDisplayName: collectionDisplayName,
-			BrunoCollection:       config.BrunoCollection,
-		})
-		if err != nil {
-			log.SetErrorCategory(log.ErrorConfiguration)
-			return nil, errors.Wrap(err, "error on executing template")
+	templ, err := newBrunoTemplate(text, funcs)
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return "", errors.Wrapf(err, "could not parse %v template", description)
+	}
+	buf := new(bytes.Buffer)
+	err = templ.Execute(buf, TemplateConfig{
+		Config:                config,
+		CollectionDisplayName: brunoCollectionDisplayName(config),
+		BrunoCollection:       brunoCollection,
+	})
+	if err != nil {
+		log.SetErrorCategory(log.ErrorConfiguration)
+		return "", errors.Wrapf(err, "error on executing %v template", description)
+	}
+	return buf.String(), nil
+}
+
+// newBrunoTemplate parses a template with the functions available within runOptions and the given additional functions
+func newBrunoTemplate(text string, funcs template.FuncMap) (*template.Template, error) {
+	return template.New("template").Funcs(template.FuncMap{
+		"getenv": func(varName string) string {
+			return os.Getenv(varName)
+		},
+	}).Funcs(funcs).Parse(text)
+}
+
+// validateBrunoTemplates parses all templates up front and reports every parse error at once,
+// instead of failing on the first one during the resolution
+func validateBrunoTemplates(texts []string, description string) error {
+	parseErrors := []string{}
+	for i, text := range texts {
+		if _, err := newBrunoTemplate(text, nil); err != nil {
+			parseErrors = append(parseErrors, fmt.Sprintf("entry %d '%v': %v", i, text, err))
 		}
-		cmd = append(cmd, buf.String())
 	}
+	if len(parseErrors) == 0 {
+		return nil
+	}
+	log.SetErrorCategory(log.ErrorConfiguration)
+	return fmt.Errorf("could not parse %v templates (%d errors): %v", description, len(parseErrors), strings.Join(parseErrors, "; "))
+}
 
-	return cmd, nil
+// brunoCollectionDisplayName returns the display name of the collection, with brunoEnvironments followed by the environment
+func brunoCollectionDisplayName(config *brunoExecuteOptions) string {
+	displayName := defineBrunoCollectionDisplayName(config.BrunoCollection, config.DisplayNameSeparator)
+	if environment := brunoEnvironmentSuffix(config); environment != "" {
+		separator := config.DisplayNameSeparator
+		if separator == "" {
+			separator = "_"
+		}
+		displayName += separator + environment
+	}
+	return displayName
 }
 
-func defineBrunoCollectionDisplayName(collection string) string {
-	replacedSeparators := strings.Replace(collection, string(filepath.Separator), "_", -1)
+func defineBrunoCollectionDisplayName(collection, separator string) string {
+	if separator == "" {
+		separator = "_"
+	}
+	replacedSeparators := strings.Replace(trimBrunoCollectionPath(collection), string(filepath.Separator), separator, -1)
 	displayName := strings.Split(replacedSeparators, ".")
 	if displayName[0] == "" && len(displayName) >= 2 {
-		return displayName[1]
+		displayName = displayName[1:]
+	}
+	if displayName[0] == "" {
+		// e.g. the current directory
+		return "collection"
 	}
 	return displayName[0]
 }
 
+// brunoWorkingDirPath resolves a relative path used by Bruno CLI against workingDirectory, so that the step can access it
+func brunoWorkingDirPath(config *brunoExecuteOptions, path string) string {
+	if config.WorkingDirectory == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(config.WorkingDirectory, path)
+}
+
+// trimBrunoCollectionPath removes trailing separators, a root path is returned unchanged
+func trimBrunoCollectionPath(collection string) string {
+	trimmed := strings.TrimRight(collection, "/"+string(filepath.Separator))
+	if trimmed == "" {
+		return collection
+	}
+	return trimmed
+}
+
 func (utils brunoExecuteUtilsBundle) Getenv(key string) string {
 	return os.Getenv(key)
 }
+
+func (utils brunoExecuteUtilsBundle) Environ() []string {
+	return os.Environ()
+}
+
+// CloneGitRepository shallow-clones a repository, using the credentials of GIT_USERNAME and GIT_TOKEN if set
+func (utils brunoExecuteUtilsBundle) CloneGitRepository(url, branch, directory string) error {
+	options := &git.CloneOptions{URL: url, Depth: 1, SingleBranch: true}
+	if branch != "" {
+		options.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+	if token := os.Getenv("GIT_TOKEN"); token != "" {
+		log.RegisterSecret(token)
+		options.Auth = &githttp.BasicAuth{Username: os.Getenv("GIT_USERNAME"), Password: token}
+	}
+	_, err := git.PlainClone(directory, false, options)
+	return err
+}
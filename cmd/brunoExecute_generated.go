@@ -24,39 +24,153 @@ import (
 )
 
 type brunoExecuteOptions struct {
-	BrunoCollection        string   `json:"brunoCollection,omitempty"`
-	RunOptions             []string `json:"runOptions,omitempty"`
-	BrunoInstallCommand    string   `json:"brunoInstallCommand,omitempty"`
-	BrunoEnvironment       string   `json:"brunoEnvironment,omitempty"`
-	BrunoGlobalEnv         string   `json:"brunoGlobalEnv,omitempty"`
-	EnvVars                []string `json:"envVars,omitempty"`
-	EnvFile                string   `json:"envFile,omitempty"`
-	FailOnError            bool     `json:"failOnError,omitempty"`
-	Recursive              bool     `json:"recursive,omitempty"`
-	Bail                   bool     `json:"bail,omitempty"`
-	Parallel               bool     `json:"parallel,omitempty"`
-	SandboxMode            string   `json:"sandboxMode,omitempty"`
-	CsvFilePath            string   `json:"csvFilePath,omitempty"`
-	JSONFilePath           string   `json:"jsonFilePath,omitempty"`
-	IterationCount         int      `json:"iterationCount,omitempty"`
-	Tags                   string   `json:"tags,omitempty"`
-	ExcludeTags            string   `json:"excludeTags,omitempty"`
-	TestsOnly              bool     `json:"testsOnly,omitempty"`
-	ReporterJSON           string   `json:"reporterJson,omitempty"`
-	ReporterJunit          string   `json:"reporterJunit,omitempty"`
-	ReporterHtml           string   `json:"reporterHtml,omitempty"`
-	ReporterSkipAllHeaders bool     `json:"reporterSkipAllHeaders,omitempty"`
-	ReporterSkipHeaders    []string `json:"reporterSkipHeaders,omitempty"`
-	Delay                  int      `json:"delay,omitempty"`
-	Insecure               bool     `json:"insecure,omitempty"`
+	SpecFile                    string                   `json:"specFile,omitempty"`
+	BrunoCollection             string                   `json:"brunoCollection,omitempty"`
+	WorkingDirectory            string                   `json:"workingDirectory,omitempty"`
+	CollectionConfigs           []map[string]interface{} `json:"collectionConfigs,omitempty"`
+	CollectionGitURL            string                   `json:"collectionGitUrl,omitempty"`
+	CollectionGitBranch         string                   `json:"collectionGitBranch,omitempty"`
+	CollectionGitSubdir         string                   `json:"collectionGitSubdir,omitempty"`
+	BrunoCollections            []string                 `json:"brunoCollections,omitempty"`
+	DisplayNameSeparator        string                   `json:"displayNameSeparator,omitempty"`
+	ReportFilenameSuffix        string                   `json:"reportFilenameSuffix,omitempty"`
+	RunOptions                  []string                 `json:"runOptions,omitempty"`
+	BrunoSubcommand             string                   `json:"brunoSubcommand,omitempty"`
+	PackageManager              string                   `json:"packageManager,omitempty" validate:"possible-values=npm yarn pnpm"`
+	NodeBinary                  string                   `json:"nodeBinary,omitempty"`
+	MinNodeVersion              string                   `json:"minNodeVersion,omitempty"`
+	NpmBinary                   string                   `json:"npmBinary,omitempty"`
+	BrunoInstallCommand         string                   `json:"brunoInstallCommand,omitempty"`
+	CleanupInstall              bool                     `json:"cleanupInstall,omitempty"`
+	InstallRetries              int                      `json:"installRetries,omitempty"`
+	BrunoVersion                string                   `json:"brunoVersion,omitempty"`
+	NpmRegistry                 string                   `json:"npmRegistry,omitempty"`
+	ManageInstallPrefix         bool                     `json:"manageInstallPrefix,omitempty"`
+	NpmGlobalPrefix             string                   `json:"npmGlobalPrefix,omitempty"`
+	BruBinaryRelPath            string                   `json:"bruBinaryRelPath,omitempty"`
+	SkipInstallIfPresent        bool                     `json:"skipInstallIfPresent,omitempty"`
+	FallbackToTempPrefix        bool                     `json:"fallbackToTempPrefix,omitempty"`
+	BrunoEnvironment            string                   `json:"brunoEnvironment,omitempty"`
+	BrunoEnvironments           []string                 `json:"brunoEnvironments,omitempty"`
+	ForbiddenEnvironments       []string                 `json:"forbiddenEnvironments,omitempty"`
+	BrunoGlobalEnv              string                   `json:"brunoGlobalEnv,omitempty"`
+	EnvVars                     []string                 `json:"envVars,omitempty"`
+	EnvVarsFile                 string                   `json:"envVarsFile,omitempty"`
+	EnvVarPrefix                string                   `json:"envVarPrefix,omitempty"`
+	EnvFile                     string                   `json:"envFile,omitempty"`
+	FailOnError                 bool                     `json:"failOnError,omitempty"`
+	FailOnNoTests               bool                     `json:"failOnNoTests,omitempty"`
+	AllowedFailures             []string                 `json:"allowedFailures,omitempty"`
+	Recursive                   bool                     `json:"recursive,omitempty"`
+	Bail                        bool                     `json:"bail,omitempty"`
+	BailAfter                   int                      `json:"bailAfter,omitempty"`
+	Parallel                    bool                     `json:"parallel,omitempty"`
+	ParallelWorkers             int                      `json:"parallelWorkers,omitempty"`
+	SandboxMode                 string                   `json:"sandboxMode,omitempty"`
+	CsvFilePath                 string                   `json:"csvFilePath,omitempty"`
+	JSONFilePath                string                   `json:"jsonFilePath,omitempty"`
+	DataFilePrecedence          string                   `json:"dataFilePrecedence,omitempty" validate:"possible-values=csv json"`
+	IterationCount              int                      `json:"iterationCount,omitempty"`
+	TestFiles                   []string                 `json:"testFiles,omitempty"`
+	Tags                        string                   `json:"tags,omitempty"`
+	TagsMatchMode               string                   `json:"tagsMatchMode,omitempty" validate:"possible-values=all any"`
+	ExcludeTags                 string                   `json:"excludeTags,omitempty"`
+	TestsOnly                   bool                     `json:"testsOnly,omitempty"`
+	OutputFile                  string                   `json:"outputFile,omitempty"`
+	SuccessPattern              string                   `json:"successPattern,omitempty"`
+	ReportsDirectory            string                   `json:"reportsDirectory,omitempty"`
+	MergeJunitReports           bool                     `json:"mergeJunitReports,omitempty"`
+	MergedJunitPath             string                   `json:"mergedJunitPath,omitempty"`
+	SummaryMarkdownPath         string                   `json:"summaryMarkdownPath,omitempty"`
+	ResultsWebhookURL           string                   `json:"resultsWebhookUrl,omitempty"`
+	ReporterJSON                string                   `json:"reporterJson,omitempty"`
+	ReporterJunit               string                   `json:"reporterJunit,omitempty"`
+	ReporterHtml                string                   `json:"reporterHtml,omitempty"`
+	ReporterHtmlTemplate        string                   `json:"reporterHtmlTemplate,omitempty"`
+	ReporterSkipAllHeaders      bool                     `json:"reporterSkipAllHeaders,omitempty"`
+	ReporterSkipHeaders         []string                 `json:"reporterSkipHeaders,omitempty"`
+	ReporterSkipResponseHeaders []string                 `json:"reporterSkipResponseHeaders,omitempty"`
+	Delay                       int                      `json:"delay,omitempty"`
+	RequestTimeoutMs            int                      `json:"requestTimeoutMs,omitempty"`
+	Insecure                    bool                     `json:"insecure,omitempty"`
+	InsecureHosts               []string                 `json:"insecureHosts,omitempty"`
+	DisableCookies              bool                     `json:"disableCookies,omitempty"`
+	ClientCertConfig            string                   `json:"clientCertConfig,omitempty"`
+	CaCert                      string                   `json:"caCert,omitempty"`
+	Verbose                     bool                     `json:"verbose,omitempty"`
+	ExtraFlags                  []string                 `json:"extraFlags,omitempty"`
+	TimeoutSeconds              int                      `json:"timeoutSeconds,omitempty"`
+	Retries                     int                      `json:"retries,omitempty"`
+	RetryDelaySeconds           int                      `json:"retryDelaySeconds,omitempty"`
+	RetryBackoff                string                   `json:"retryBackoff,omitempty" validate:"possible-values=fixed exponential"`
+	RetryJitter                 bool                     `json:"retryJitter,omitempty"`
+	PreflightURL                string                   `json:"preflightUrl,omitempty"`
+	HttpProxy                   string                   `json:"httpProxy,omitempty"`
+	HttpsProxy                  string                   `json:"httpsProxy,omitempty"`
+	NoProxy                     string                   `json:"noProxy,omitempty"`
+	BrunoNoProxy                bool                     `json:"brunoNoProxy,omitempty"`
+	VersionCheckRetries         int                      `json:"versionCheckRetries,omitempty"`
+	SkipVersionLogging          bool                     `json:"skipVersionLogging,omitempty"`
+	RequireCleanCollection      bool                     `json:"requireCleanCollection,omitempty"`
+	MaxInstalledPackages        int                      `json:"maxInstalledPackages,omitempty"`
+	AllureOutputDir             string                   `json:"allureOutputDir,omitempty"`
+	HarOutputPath               string                   `json:"harOutputPath,omitempty"`
+	DefaultRunOptions           bool                     `json:"defaultRunOptions,omitempty"`
+	CsvResultsOutput            string                   `json:"csvResultsOutput,omitempty"`
+	AssertionsOutput            string                   `json:"assertionsOutput,omitempty"`
+	MaskURLQueryParams          []string                 `json:"maskUrlQueryParams,omitempty"`
+	FailOnDuplicateRequestNames bool                     `json:"failOnDuplicateRequestNames,omitempty"`
+	AuthSmokeRequest            string                   `json:"authSmokeRequest,omitempty"`
+	MaxP95ResponseTimeMs        int                      `json:"maxP95ResponseTimeMs,omitempty"`
+	SlowThresholdMs             int                      `json:"slowThresholdMs,omitempty"`
+	FailOnSlow                  bool                     `json:"failOnSlow,omitempty"`
+	SummarizeFailures           bool                     `json:"summarizeFailures,omitempty"`
+	IncludeBodiesOnFailure      bool                     `json:"includeBodiesOnFailure,omitempty"`
+	MaxBodyLogBytes             int                      `json:"maxBodyLogBytes,omitempty"`
+	DryRun                      bool                     `json:"dryRun,omitempty"`
+	ListOnly                    string                   `json:"listOnly,omitempty" validate:"possible-values=tags envs"`
+}
+
+type brunoExecuteCommonPipelineEnvironment struct {
+	custom struct {
+		brunoSummary string
+	}
+}
+
+func (p *brunoExecuteCommonPipelineEnvironment) persist(path, resourceName string) {
+	content := []struct {
+		category string
+		name     string
+		value    interface{}
+	}{
+		{category: "custom", name: "brunoSummary", value: p.custom.brunoSummary},
+	}
+
+	errCount := 0
+	for _, param := range content {
+		err := piperenv.SetResourceParameter(path, resourceName, filepath.Join(param.category, param.name), param.value)
+		if err != nil {
+			log.Entry().WithError(err).Error("Error persisting piper environment.")
+			errCount++
+		}
+	}
+	if errCount > 0 {
+		log.Entry().Error("failed to persist Piper environment")
+	}
 }
 
 type brunoExecuteInflux struct {
 	step_data struct {
 		fields struct {
-			bruno bool
+			bruno                      bool
+			bruno_tests_total          int
+			bruno_tests_failed         int
+			bruno_requests_total       int
+			bruno_duration_ms          int
+			bruno_p95_response_time_ms int
 		}
 		tags struct {
+			environment string
 		}
 	}
 }
@@ -69,6 +183,12 @@ func (i *brunoExecuteInflux) persist(path, resourceName string) {
 		value       interface{}
 	}{
 		{valType: config.InfluxField, measurement: "step_data", name: "bruno", value: i.step_data.fields.bruno},
+		{valType: config.InfluxField, measurement: "step_data", name: "bruno_tests_total", value: i.step_data.fields.bruno_tests_total},
+		{valType: config.InfluxField, measurement: "step_data", name: "bruno_tests_failed", value: i.step_data.fields.bruno_tests_failed},
+		{valType: config.InfluxField, measurement: "step_data", name: "bruno_requests_total", value: i.step_data.fields.bruno_requests_total},
+		{valType: config.InfluxField, measurement: "step_data", name: "bruno_duration_ms", value: i.step_data.fields.bruno_duration_ms},
+		{valType: config.InfluxField, measurement: "step_data", name: "bruno_p95_response_time_ms", value: i.step_data.fields.bruno_p95_response_time_ms},
+		{valType: config.InfluxTag, measurement: "step_data", name: "environment", value: i.step_data.tags.environment},
 	}
 
 	errCount := 0
@@ -128,6 +248,7 @@ func BrunoExecuteCommand() *cobra.Command {
 	metadata := brunoExecuteMetadata()
 	var stepConfig brunoExecuteOptions
 	var startTime time.Time
+	var commonPipelineEnvironment brunoExecuteCommonPipelineEnvironment
 	var influx brunoExecuteInflux
 	var reports brunoExecuteReports
 	var logCollector *log.CollectorHook
@@ -137,7 +258,10 @@ func BrunoExecuteCommand() *cobra.Command {
 	var createBrunoExecuteCmd = &cobra.Command{
 		Use:   STEP_NAME,
 		Short: "Installs Bruno CLI and executes specified Bruno API collections.",
-		Long:  `This script executes [Bruno](https://www.usebruno.com/) API tests from a collection via the [Bruno CLI](https://docs.usebruno.com/bru-cli/overview) command line tool.`,
+		Long: `This script executes [Bruno](https://www.usebruno.com/) API tests from a collection via the [Bruno CLI](https://docs.usebruno.com/bru-cli/overview) command line tool.
+
+After the run, the file ` + "`" + `brunoExecute_results.json` + "`" + ` in the workspace contains the exit code of the Bruno CLI.
+If ` + "`" + `reporterJson` + "`" + ` is set, it also contains the total and failed number of requests as well as the names of the failed requests.`,
 		PreRunE: func(cmd *cobra.Command, _ []string) error {
 			startTime = time.Now()
 			log.SetStepName(STEP_NAME)
@@ -168,6 +292,7 @@ func BrunoExecuteCommand() *cobra.Command {
 				}
 			}
 			log.SetStepErrors(stepErrors)
+			log.RegisterSecret(stepConfig.ResultsWebhookURL)
 
 			if len(GeneralConfig.HookConfig.SentryConfig.Dsn) > 0 {
 				sentryHook := log.NewSentryHook(GeneralConfig.HookConfig.SentryConfig.Dsn, GeneralConfig.CorrelationID)
@@ -204,6 +329,7 @@ func BrunoExecuteCommand() *cobra.Command {
 			stepTelemetryData := telemetry.CustomData{}
 			stepTelemetryData.ErrorCode = "1"
 			handler := func() {
+				commonPipelineEnvironment.persist(GeneralConfig.EnvRootPath, "commonPipelineEnvironment")
 				influx.persist(GeneralConfig.EnvRootPath, "influx")
 				reports.persist(stepConfig, GeneralConfig.GCPJsonKeyFilePath, GeneralConfig.GCSBucketId, GeneralConfig.GCSFolderPath, GeneralConfig.GCSSubFolder)
 				config.RemoveVaultSecretFiles()
@@ -262,7 +388,7 @@ func BrunoExecuteCommand() *cobra.Command {
 			log.DeferExitHandler(handler)
 			defer handler()
 			telemetryClient.Initialize(STEP_NAME)
-			brunoExecute(stepConfig, &stepTelemetryData, &influx)
+			brunoExecute(stepConfig, &stepTelemetryData, &commonPipelineEnvironment, &influx)
 			stepTelemetryData.ErrorCode = "0"
 			log.Entry().Info("SUCCESS")
 		},
@@ -273,33 +399,112 @@ func BrunoExecuteCommand() *cobra.Command {
 }
 
 func addBrunoExecuteFlags(cmd *cobra.Command, stepConfig *brunoExecuteOptions) {
-	cmd.Flags().StringVar(&stepConfig.BrunoCollection, "brunoCollection", os.Getenv("PIPER_brunoCollection"), "Path to the Bruno collection directory (containing bruno.json).")
-	cmd.Flags().StringSliceVar(&stepConfig.RunOptions, "runOptions", []string{`run`, `{{.BrunoCollection}}`, `--reporter-junit`, `target/bruno/TEST-{{.CollectionDisplayName}}.xml`, `--reporter-html`, `target/bruno/TEST-{{.CollectionDisplayName}}.html`}, "The Bruno CLI run options. Supports Go templating with variables like {{.BrunoCollection}} and {{.CollectionDisplayName}}.")
-	cmd.Flags().StringVar(&stepConfig.BrunoInstallCommand, "brunoInstallCommand", `npm install @usebruno/cli --global --quiet`, "The shell command to install Bruno CLI.")
+	cmd.Flags().StringVar(&stepConfig.SpecFile, "specFile", os.Getenv("PIPER_specFile"), "Path to a JSON or YAML file with further options of this step, e.g. `runOptions`, `envVars` or `tags`, using the same names as the step parameters.")
+	cmd.Flags().StringVar(&stepConfig.BrunoCollection, "brunoCollection", os.Getenv("PIPER_brunoCollection"), "Path to the Bruno collection directory (containing bruno.json). Mandatory unless `brunoCollections` is set, falls back to the environment variable `BRUNO_COLLECTION` otherwise.")
+	cmd.Flags().StringVar(&stepConfig.WorkingDirectory, "workingDirectory", os.Getenv("PIPER_workingDirectory"), "Directory to run the Bruno CLI in. Relative paths passed to the Bruno CLI, e.g. of the collections and reports, are resolved against it.")
+
+	cmd.Flags().StringVar(&stepConfig.CollectionGitURL, "collectionGitUrl", os.Getenv("PIPER_collectionGitUrl"), "URL of a git repository containing the Bruno collection. If set, the repository is shallow-cloned into a temporary directory, which is used as `brunoCollection`.")
+	cmd.Flags().StringVar(&stepConfig.CollectionGitBranch, "collectionGitBranch", os.Getenv("PIPER_collectionGitBranch"), "Branch of `collectionGitUrl` to clone. Defaults to the default branch of the repository.")
+	cmd.Flags().StringVar(&stepConfig.CollectionGitSubdir, "collectionGitSubdir", os.Getenv("PIPER_collectionGitSubdir"), "Path of the Bruno collection within the repository cloned from `collectionGitUrl`. Defaults to the repository root.")
+	cmd.Flags().StringSliceVar(&stepConfig.BrunoCollections, "brunoCollections", []string{}, "Paths to several Bruno collection directories, each run separately with its own `CollectionDisplayName`. Takes precedence over `brunoCollection`.")
+	cmd.Flags().StringVar(&stepConfig.DisplayNameSeparator, "displayNameSeparator", `_`, "Replaces the path separators of the collection path in its display name, e.g. used for the report names (`{{.CollectionDisplayName}}`).")
+	cmd.Flags().StringVar(&stepConfig.ReportFilenameSuffix, "reportFilenameSuffix", os.Getenv("PIPER_reportFilenameSuffix"), "Suffix inserted before the file extension of the report paths generated within `reportsDirectory` and of the `runOptions` using `{{.CollectionDisplayName}}`, e.g. the build number to keep the reports of each build when archiving them. Path separators are removed.")
+	cmd.Flags().StringSliceVar(&stepConfig.RunOptions, "runOptions", []string{`run`, `{{.BrunoCollection}}`, `--reporter-junit`, `target/bruno/TEST-{{.CollectionDisplayName}}.xml`, `--reporter-html`, `target/bruno/TEST-{{.CollectionDisplayName}}.html`}, "The Bruno CLI run options, `brunoSubcommand` is prepended if they do not start with it. Supports Go templating with variables like {{.BrunoCollection}} and {{.CollectionDisplayName}}.")
+	cmd.Flags().StringVar(&stepConfig.BrunoSubcommand, "brunoSubcommand", `run`, "The subcommand of the Bruno CLI, which is prepended to `runOptions` unless they already start with it. This allows omitting `run` from `runOptions`.")
+	cmd.Flags().StringVar(&stepConfig.PackageManager, "packageManager", `npm`, "The package manager used to install the Bruno CLI. `brunoInstallCommand` is only used with npm, yarn and pnpm install the `@usebruno/cli` package globally.")
+	cmd.Flags().StringVar(&stepConfig.NodeBinary, "nodeBinary", `node`, "Path or name of the Node.js executable, e.g. if Node.js is installed via nvm and not part of the PATH.")
+	cmd.Flags().StringVar(&stepConfig.MinNodeVersion, "minNodeVersion", os.Getenv("PIPER_minNodeVersion"), "Minimum Node.js version required to run the Bruno CLI, e.g. `18` or `20.11.0`. The step fails early if the version of `nodeBinary` is lower.")
+	cmd.Flags().StringVar(&stepConfig.NpmBinary, "npmBinary", `npm`, "Path or name of the npm executable. Replaces `npm` at the start of `brunoInstallCommand`.")
+	cmd.Flags().StringVar(&stepConfig.BrunoInstallCommand, "brunoInstallCommand", `npm install @usebruno/cli --global --quiet`, "The shell command to install Bruno CLI if `packageManager` is npm.")
+	cmd.Flags().BoolVar(&stepConfig.CleanupInstall, "cleanupInstall", false, "Uninstalls the Bruno CLI after the run, also if it fails, e.g. on shared agents. A Bruno CLI found with `skipInstallIfPresent` is kept.")
+	cmd.Flags().IntVar(&stepConfig.InstallRetries, "installRetries", 0, "Number of times the installation of the Bruno CLI is retried with an increasing delay if it fails, e.g. because of an unavailable npm registry.")
+	cmd.Flags().StringVar(&stepConfig.BrunoVersion, "brunoVersion", os.Getenv("PIPER_brunoVersion"), "Version of the Bruno CLI to install, e.g. `2.3.0`. Replaces the `@usebruno/cli` package of `brunoInstallCommand` with the pinned version.")
+	cmd.Flags().StringVar(&stepConfig.NpmRegistry, "npmRegistry", os.Getenv("PIPER_npmRegistry"), "URL of the npm registry to install the Bruno CLI from (--registry), e.g. an internal mirror.")
+	cmd.Flags().BoolVar(&stepConfig.ManageInstallPrefix, "manageInstallPrefix", true, "Appends `--prefix` with `npmGlobalPrefix` to `brunoInstallCommand` and the uninstall command of npm. Disable it if `brunoInstallCommand` sets its own prefix, the command is then used verbatim. The Bruno CLI is then run from `npmGlobalPrefix` with `bruBinaryRelPath` if `npmGlobalPrefix` is set, otherwise `bru` is looked up in the PATH.")
+	cmd.Flags().StringVar(&stepConfig.NpmGlobalPrefix, "npmGlobalPrefix", `~/.npm-global`, "The global prefix the Bruno CLI is installed to (--prefix), the Bruno CLI is called from `bruBinaryRelPath` within it. A leading `~` is resolved to the home directory when calling the Bruno CLI.")
+	cmd.Flags().StringVar(&stepConfig.BruBinaryRelPath, "bruBinaryRelPath", `bin/bru`, "Path of the Bruno CLI relative to `npmGlobalPrefix`, e.g. `node_modules/.bin/bru` for package managers with a different layout. The step fails if the Bruno CLI does not exist at this path after the installation.")
+	cmd.Flags().BoolVar(&stepConfig.SkipInstallIfPresent, "skipInstallIfPresent", false, "Skips the installation of the Bruno CLI if it is already present at `bruBinaryRelPath` within `npmGlobalPrefix`, e.g. on agents with a pre-installed Bruno CLI.")
+	cmd.Flags().BoolVar(&stepConfig.FallbackToTempPrefix, "fallbackToTempPrefix", false, "Installs the Bruno CLI to a temporary npm global prefix if `npmGlobalPrefix` is not writable. Otherwise the step fails in this case.")
 	cmd.Flags().StringVar(&stepConfig.BrunoEnvironment, "brunoEnvironment", os.Getenv("PIPER_brunoEnvironment"), "Bruno environment name to use for the collection run (--env).")
+	cmd.Flags().StringSliceVar(&stepConfig.BrunoEnvironments, "brunoEnvironments", []string{}, "Bruno environment names to run each collection with one after another (--env). Takes precedence over `brunoEnvironment`.")
+	cmd.Flags().StringSliceVar(&stepConfig.ForbiddenEnvironments, "forbiddenEnvironments", []string{}, "Bruno environment names the collections must not be run with, e.g. production environments with destructive tests. The step fails before any test runs if `brunoEnvironment`, `brunoEnvironments` or the environment of `collectionConfigs` matches one of them, ignoring the case.")
 	cmd.Flags().StringVar(&stepConfig.BrunoGlobalEnv, "brunoGlobalEnv", os.Getenv("PIPER_brunoGlobalEnv"), "Bruno global/workspace-level environment name (--global-env).")
-	cmd.Flags().StringSliceVar(&stepConfig.EnvVars, "envVars", []string{}, "Environment variable overrides in key=value format (--env-var). Can be specified multiple times.")
-	cmd.Flags().StringVar(&stepConfig.EnvFile, "envFile", os.Getenv("PIPER_envFile"), "Path to environment file (.bru or .json) to use for the collection run (--env-file).")
+	cmd.Flags().StringSliceVar(&stepConfig.EnvVars, "envVars", []string{}, "Environment variable overrides in key=value format (--env-var). Can be specified multiple times. Values support the templates of `runOptions` and in addition `{{vault \"path/key\"}}`, which reads the key of a secret from the Vault configured via `vaultServerUrl`.")
+	cmd.Flags().StringVar(&stepConfig.EnvVarsFile, "envVarsFile", os.Getenv("PIPER_envVarsFile"), "Path to a .env style file with `KEY=VALUE` pairs, which are passed in addition to `envVars` (--env-var). Blank lines and lines starting with `#` are ignored.")
+	cmd.Flags().StringVar(&stepConfig.EnvVarPrefix, "envVarPrefix", os.Getenv("PIPER_envVarPrefix"), "Prefix of environment variables of the agent to pass to the Bruno CLI (--env-var), e.g. `BRUNO_VAR_`. The prefix is removed from the names, entries of `envVars` with the same name take precedence.")
+	cmd.Flags().StringVar(&stepConfig.EnvFile, "envFile", os.Getenv("PIPER_envFile"), "Path to environment file (.bru or .json) to use for the collection run (--env-file). The file must exist, files other than .bru must contain valid JSON.")
 	cmd.Flags().BoolVar(&stepConfig.FailOnError, "failOnError", true, "Defines the behavior in case tests fail. When set to true, the step will fail if any test fails.")
+	cmd.Flags().BoolVar(&stepConfig.FailOnNoTests, "failOnNoTests", false, "Fails the step if no requests were executed, e.g. because `tags` or `excludeTags` filter out all requests. Requires `reporterJson`, otherwise the check is skipped with a warning.")
+	cmd.Flags().StringSliceVar(&stepConfig.AllowedFailures, "allowedFailures", []string{}, "Names of requests whose failures are only logged as warnings, e.g. of known flaky requests. The step only fails if other requests failed as well. Requires `reporterJson`, otherwise the option is ignored with a warning.")
 	cmd.Flags().BoolVar(&stepConfig.Recursive, "recursive", false, "Run requests recursively in subdirectories (-r).")
 	cmd.Flags().BoolVar(&stepConfig.Bail, "bail", false, "Stop execution after a failure of a request, test, or assertion (--bail).")
+	cmd.Flags().IntVar(&stepConfig.BailAfter, "bailAfter", 0, "Stop execution after the given number of failures. Takes precedence over `bail`.")
 	cmd.Flags().BoolVar(&stepConfig.Parallel, "parallel", false, "Run requests in parallel (--parallel). Default is sequential execution.")
-	cmd.Flags().StringVar(&stepConfig.SandboxMode, "sandboxMode", `safe`, "JavaScript sandbox mode - \"safe\" (default) or \"developer\" (--sandbox).")
-	cmd.Flags().StringVar(&stepConfig.CsvFilePath, "csvFilePath", os.Getenv("PIPER_csvFilePath"), "Path to CSV file for data-driven testing (--csv-file-path).")
-	cmd.Flags().StringVar(&stepConfig.JSONFilePath, "jsonFilePath", os.Getenv("PIPER_jsonFilePath"), "Path to JSON data file for data-driven testing (--json-file-path).")
+	cmd.Flags().IntVar(&stepConfig.ParallelWorkers, "parallelWorkers", 0, "Number of requests to run in parallel. A positive value enables `parallel`, zero or a negative value keeps the default of the Bruno CLI.")
+	cmd.Flags().StringVar(&stepConfig.SandboxMode, "sandboxMode", `safe`, "JavaScript sandbox mode - \"safe\" (default) or \"developer\" (--sandbox). If empty, `--sandbox` is not passed.")
+	cmd.Flags().StringVar(&stepConfig.CsvFilePath, "csvFilePath", os.Getenv("PIPER_csvFilePath"), "Path to CSV file for data-driven testing (--csv-file-path). Supports the templates of `runOptions`, e.g. `data/{{.CollectionDisplayName}}.csv`.")
+	cmd.Flags().StringVar(&stepConfig.JSONFilePath, "jsonFilePath", os.Getenv("PIPER_jsonFilePath"), "Path to JSON data file for data-driven testing (--json-file-path). Supports the templates of `runOptions`, e.g. `data/{{.CollectionDisplayName}}.json`.")
+	cmd.Flags().StringVar(&stepConfig.DataFilePrecedence, "dataFilePrecedence", os.Getenv("PIPER_dataFilePrecedence"), "Data file to use if both `csvFilePath` and `jsonFilePath` are set, since Bruno CLI only supports one. If not set, the step fails on such a configuration.")
 	cmd.Flags().IntVar(&stepConfig.IterationCount, "iterationCount", 0, "Number of times to run the collection (--iteration-count).")
+	cmd.Flags().StringSliceVar(&stepConfig.TestFiles, "testFiles", []string{}, "Paths of `.bru` files relative to the collection to run instead of the whole collection. Cannot be combined with `tags` or `excludeTags`.")
 	cmd.Flags().StringVar(&stepConfig.Tags, "tags", os.Getenv("PIPER_tags"), "Only run requests that have ALL of the specified tags, comma-separated (--tags).")
+	cmd.Flags().StringVar(&stepConfig.TagsMatchMode, "tagsMatchMode", `all`, "Whether requests need to have `all` or `any` of the specified `tags` to be run. The Bruno CLI only supports `all`, with `any` a warning is logged and requests with all of the tags are run.")
 	cmd.Flags().StringVar(&stepConfig.ExcludeTags, "excludeTags", os.Getenv("PIPER_excludeTags"), "Skip requests that have ANY of the specified tags, comma-separated (--exclude-tags).")
 	cmd.Flags().BoolVar(&stepConfig.TestsOnly, "testsOnly", false, "Only run requests that have tests or active assertions (--tests-only).")
+	cmd.Flags().StringVar(&stepConfig.OutputFile, "outputFile", os.Getenv("PIPER_outputFile"), "Path of a file the text output of Bruno CLI is written to in addition to the log. The file is also written if the tests fail.")
+	cmd.Flags().StringVar(&stepConfig.SuccessPattern, "successPattern", os.Getenv("PIPER_successPattern"), "Regular expression the text output of Bruno CLI has to match, e.g. a line printed by a wrapper script on success. A run which exits successfully but whose output does not match fails like a run with failing tests.")
+	cmd.Flags().StringVar(&stepConfig.ReportsDirectory, "reportsDirectory", os.Getenv("PIPER_reportsDirectory"), "Directory to write the JSON, JUnit and HTML reports of each collection to, named `TEST-<collection>` with the respective extension. Reporters configured explicitly, also within `runOptions`, take precedence.")
+	cmd.Flags().BoolVar(&stepConfig.MergeJunitReports, "mergeJunitReports", false, "Merges the JUnit reports of all collections into a single report at `mergedJunitPath`. Reports which do not exist, e.g. because a collection crashed, are skipped.")
+	cmd.Flags().StringVar(&stepConfig.MergedJunitPath, "mergedJunitPath", `brunoExecute_junit.xml`, "Path of the merged JUnit report written with `mergeJunitReports`. Use a path not matched by the pattern of the JUnit publisher for the individual reports to avoid counting the tests twice.")
+	cmd.Flags().StringVar(&stepConfig.SummaryMarkdownPath, "summaryMarkdownPath", os.Getenv("PIPER_summaryMarkdownPath"), "Path to write a Markdown summary of the results of all collections to, e.g. to post it as pull request comment. The request counts and durations require `reporterJson`.")
+	cmd.Flags().StringVar(&stepConfig.ResultsWebhookURL, "resultsWebhookUrl", os.Getenv("PIPER_resultsWebhookUrl"), "URL to post the overall status and the passed and failed request counts of the collections to as JSON after the run, e.g. of a chat or ops webhook. The request counts require `reporterJson`.")
 	cmd.Flags().StringVar(&stepConfig.ReporterJSON, "reporterJson", os.Getenv("PIPER_reporterJson"), "Path to generate a JSON report (--reporter-json).")
 	cmd.Flags().StringVar(&stepConfig.ReporterJunit, "reporterJunit", os.Getenv("PIPER_reporterJunit"), "Path to generate a JUnit report (--reporter-junit). Supports Go templating.")
 	cmd.Flags().StringVar(&stepConfig.ReporterHtml, "reporterHtml", os.Getenv("PIPER_reporterHtml"), "Path to generate an HTML report (--reporter-html). Supports Go templating.")
+	cmd.Flags().StringVar(&stepConfig.ReporterHtmlTemplate, "reporterHtmlTemplate", os.Getenv("PIPER_reporterHtmlTemplate"), "Template file or theme name for the HTML report. The Bruno CLI does not support customizing the HTML report yet, so the option is only validated and ignored with a warning.")
 	cmd.Flags().BoolVar(&stepConfig.ReporterSkipAllHeaders, "reporterSkipAllHeaders", false, "Skip all headers in the report (--reporter-skip-all-headers).")
 	cmd.Flags().StringSliceVar(&stepConfig.ReporterSkipHeaders, "reporterSkipHeaders", []string{}, "Skip specific headers in the report (--reporter-skip-headers).")
+	cmd.Flags().StringSliceVar(&stepConfig.ReporterSkipResponseHeaders, "reporterSkipResponseHeaders", []string{}, "Skip specific response headers in the report, e.g. `Set-Cookie`.")
 	cmd.Flags().IntVar(&stepConfig.Delay, "delay", 0, "Delay between each request in milliseconds (--delay).")
+	cmd.Flags().IntVar(&stepConfig.RequestTimeoutMs, "requestTimeoutMs", 0, "Timeout of each request in milliseconds (--timeout), so that single slow endpoints fail fast instead of blocking the run. Ignored with a warning if the installed Bruno CLI does not support the flag. `0` keeps the default of the Bruno CLI.")
 	cmd.Flags().BoolVar(&stepConfig.Insecure, "insecure", false, "Allow insecure server connections (--insecure).")
+	cmd.Flags().StringSliceVar(&stepConfig.InsecureHosts, "insecureHosts", []string{}, "Hosts to allow insecure server connections to.")
+	cmd.Flags().BoolVar(&stepConfig.DisableCookies, "disableCookies", false, "Disables the cookie jar, so that cookies are not persisted and sent across the requests of the run (--disable-cookies).")
+	cmd.Flags().StringVar(&stepConfig.ClientCertConfig, "clientCertConfig", os.Getenv("PIPER_clientCertConfig"), "Path to a client certificate configuration for mutual TLS (--client-cert-config). The file must exist.")
+	cmd.Flags().StringVar(&stepConfig.CaCert, "caCert", os.Getenv("PIPER_caCert"), "Path to a CA certificate bundle to verify the server certificates with (--cacert), e.g. for internal CAs. The file must exist. Ignored if `insecure` is set.")
+	cmd.Flags().BoolVar(&stepConfig.Verbose, "verbose", false, "Enables the verbose output of Bruno CLI (--verbose). Implied if the step runs with the debug log level.")
+	cmd.Flags().StringSliceVar(&stepConfig.ExtraFlags, "extraFlags", []string{}, "Additional flags appended to the Bruno CLI command after all generated options, e.g. for flags not yet supported by the step. Each entry is passed as a single argument.")
+	cmd.Flags().IntVar(&stepConfig.TimeoutSeconds, "timeoutSeconds", 0, "Terminates the Bruno CLI if the tests of a collection do not finish within the given number of seconds. A value of 0 disables the timeout.")
+	cmd.Flags().IntVar(&stepConfig.Retries, "retries", 0, "Number of additional attempts to run a collection whose Bruno tests failed, e.g. against flaky shared environments.")
+	cmd.Flags().IntVar(&stepConfig.RetryDelaySeconds, "retryDelaySeconds", 0, "Delay in seconds between the attempts to run a collection, see `retries`. With an exponential `retryBackoff`, this is the delay before the first retry. The delay is capped at 5 minutes.")
+	cmd.Flags().StringVar(&stepConfig.RetryBackoff, "retryBackoff", `fixed`, "Whether the delay between the attempts to run a collection stays the same (`fixed`) or doubles with every retry (`exponential`) up to 5 minutes.")
+	cmd.Flags().BoolVar(&stepConfig.RetryJitter, "retryJitter", false, "Randomizes the delay between the attempts to run a collection to between half of and the full delay, so that parallel jobs do not retry at the same time.")
+	cmd.Flags().StringVar(&stepConfig.PreflightURL, "preflightUrl", os.Getenv("PIPER_preflightUrl"), "URL requested before the Bruno CLI runs, e.g. the base URL of the tested environment. The step fails early if the URL cannot be reached or responds with a server error.")
+	cmd.Flags().StringVar(&stepConfig.HttpProxy, "httpProxy", os.Getenv("PIPER_httpProxy"), "HTTP proxy passed to the Bruno CLI as `HTTP_PROXY` and `http_proxy` environment variables.")
+	cmd.Flags().StringVar(&stepConfig.HttpsProxy, "httpsProxy", os.Getenv("PIPER_httpsProxy"), "HTTPS proxy passed to the Bruno CLI as `HTTPS_PROXY` and `https_proxy` environment variables.")
+	cmd.Flags().StringVar(&stepConfig.NoProxy, "noProxy", os.Getenv("PIPER_noProxy"), "Hosts excluded from proxying, passed to the Bruno CLI as `NO_PROXY` and `no_proxy` environment variables.")
+	cmd.Flags().BoolVar(&stepConfig.BrunoNoProxy, "brunoNoProxy", false, "Disables all proxy settings of Bruno CLI, both the ones defined in the collection and the system proxy (--noproxy).")
+	cmd.Flags().IntVar(&stepConfig.VersionCheckRetries, "versionCheckRetries", 0, "Number of additional attempts for logging the node and npm versions in case the call fails transiently. Capped at 3.")
+	cmd.Flags().BoolVar(&stepConfig.SkipVersionLogging, "skipVersionLogging", false, "Skips logging the node and npm versions. Without the version calls, a missing or broken Node.js installation is no longer reported as an infrastructure error but surfaces at the installation or the run of the Bruno CLI, and `minNodeVersion` is not checked.")
+	cmd.Flags().BoolVar(&stepConfig.RequireCleanCollection, "requireCleanCollection", false, "Fails the step if the Bruno collection directory contains uncommitted git changes.")
+	cmd.Flags().IntVar(&stepConfig.MaxInstalledPackages, "maxInstalledPackages", 0, "Fails the step if the Bruno CLI installation added more npm packages than specified. A value of 0 disables the check. Only supported with npm.")
+	cmd.Flags().StringVar(&stepConfig.AllureOutputDir, "allureOutputDir", os.Getenv("PIPER_allureOutputDir"), "Directory to write Allure results to, one result file per request. Requires `reporterJson` to be set.")
+	cmd.Flags().StringVar(&stepConfig.HarOutputPath, "harOutputPath", os.Getenv("PIPER_harOutputPath"), "Path to write an HTTP archive (HAR) of the requests to. The Bruno CLI cannot write HTTP archives, so the option is ignored with a warning.")
+	cmd.Flags().BoolVar(&stepConfig.DefaultRunOptions, "defaultRunOptions", false, "Falls back to `run {{.BrunoCollection}}` if `runOptions` is empty. Otherwise the step fails on empty `runOptions`.")
+	cmd.Flags().StringVar(&stepConfig.CsvResultsOutput, "csvResultsOutput", os.Getenv("PIPER_csvResultsOutput"), "Path to write a CSV file with one row per request (request, method, url, status, duration_ms, passed). Requires `reporterJson` to be set.")
+	cmd.Flags().StringVar(&stepConfig.AssertionsOutput, "assertionsOutput", os.Getenv("PIPER_assertionsOutput"), "Path to write a JSON lines file with one entry per assertion (request, name, operator, expected, actual, passed). Values of `envVars` are masked. Requires `reporterJson` to be set.")
+	cmd.Flags().StringSliceVar(&stepConfig.MaskURLQueryParams, "maskUrlQueryParams", []string{}, "Names of URL query parameters whose values are masked in all outputs generated by the step, e.g. tokens or tenant IDs.")
+	cmd.Flags().BoolVar(&stepConfig.FailOnDuplicateRequestNames, "failOnDuplicateRequestNames", false, "Fails the step if the Bruno JSON report contains several requests with the same name, which makes the reports ambiguous. Requires `reporterJson` to be set.")
+	cmd.Flags().StringVar(&stepConfig.AuthSmokeRequest, "authSmokeRequest", os.Getenv("PIPER_authSmokeRequest"), "Request of the collection (e.g. `auth/login.bru`) which is run first to verify connectivity and authentication. If it fails, the collection is not run and counts as failed, the remaining collections are run nevertheless.")
+	cmd.Flags().IntVar(&stepConfig.MaxP95ResponseTimeMs, "maxP95ResponseTimeMs", 0, "Fails the step if the 95th percentile of the response times of all requests exceeds the given milliseconds. A value of 0 disables the check. Requires `reporterJson` to be set.")
+	cmd.Flags().IntVar(&stepConfig.SlowThresholdMs, "slowThresholdMs", 0, "Logs the requests whose response time exceeds the given milliseconds and lists them in the Markdown summary. A value of 0 disables the check. Requires `reporterJson`, otherwise the check is skipped with a warning.")
+	cmd.Flags().BoolVar(&stepConfig.FailOnSlow, "failOnSlow", false, "Fails the step if any request exceeds `slowThresholdMs`.")
+	cmd.Flags().BoolVar(&stepConfig.SummarizeFailures, "summarizeFailures", false, "Logs a summary of each failed request with the messages of its failed assertions and tests, also if `failOnError` is false. Requires `reporterJson` to be set.")
+	cmd.Flags().BoolVar(&stepConfig.IncludeBodiesOnFailure, "includeBodiesOnFailure", false, "Adds the request and response bodies of failed requests to the failure summary, see `summarizeFailures` which is implied. Requires `reporterJson` to be set.")
+	cmd.Flags().IntVar(&stepConfig.MaxBodyLogBytes, "maxBodyLogBytes", 4096, "Maximum number of bytes of each body logged with `includeBodiesOnFailure`, longer bodies are truncated.")
+	cmd.Flags().BoolVar(&stepConfig.DryRun, "dryRun", false, "Only logs the resolved Bruno CLI command without installing or executing Bruno.")
+	cmd.Flags().StringVar(&stepConfig.ListOnly, "listOnly", os.Getenv("PIPER_listOnly"), "Only logs the tags (`tags`) or environments (`envs`) defined by the collections without installing or executing Bruno, e.g. to verify `tags` and `brunoEnvironment`.")
 
-	cmd.MarkFlagRequired("brunoCollection")
 }
 
 // retrieve step metadata
@@ -316,53 +521,70 @@ func brunoExecuteMetadata() config.StepData {
 					{Name: "tests", Type: "stash"},
 				},
 				Parameters: []config.StepParameters{
+					{
+						Name:        "specFile",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_specFile"),
+					},
 					{
 						Name:        "brunoCollection",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
-						Mandatory:   true,
+						Mandatory:   false,
 						Aliases:     []config.Alias{},
 						Default:     os.Getenv("PIPER_brunoCollection"),
 					},
 					{
-						Name:        "runOptions",
+						Name:        "workingDirectory",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
-						Type:        "[]string",
+						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     []string{`run`, `{{.BrunoCollection}}`, `--reporter-junit`, `target/bruno/TEST-{{.CollectionDisplayName}}.xml`, `--reporter-html`, `target/bruno/TEST-{{.CollectionDisplayName}}.html`},
+						Default:     os.Getenv("PIPER_workingDirectory"),
 					},
 					{
-						Name:        "brunoInstallCommand",
+						Name:        "collectionConfigs",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]map[string]interface{}",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+					},
+					{
+						Name:        "collectionGitUrl",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     `npm install @usebruno/cli --global --quiet`,
+						Default:     os.Getenv("PIPER_collectionGitUrl"),
 					},
 					{
-						Name:        "brunoEnvironment",
+						Name:        "collectionGitBranch",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_brunoEnvironment"),
+						Default:     os.Getenv("PIPER_collectionGitBranch"),
 					},
 					{
-						Name:        "brunoGlobalEnv",
+						Name:        "collectionGitSubdir",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_brunoGlobalEnv"),
+						Default:     os.Getenv("PIPER_collectionGitSubdir"),
 					},
 					{
-						Name:        "envVars",
+						Name:        "brunoCollections",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "[]string",
@@ -371,79 +593,97 @@ func brunoExecuteMetadata() config.StepData {
 						Default:     []string{},
 					},
 					{
-						Name:        "envFile",
+						Name:        "displayNameSeparator",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_envFile"),
+						Default:     `_`,
 					},
 					{
-						Name:        "failOnError",
+						Name:        "reportFilenameSuffix",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
-						Type:        "bool",
+						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     true,
+						Default:     os.Getenv("PIPER_reportFilenameSuffix"),
 					},
 					{
-						Name:        "recursive",
+						Name:        "runOptions",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
-						Type:        "bool",
+						Type:        "[]string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     false,
+						Default:     []string{`run`, `{{.BrunoCollection}}`, `--reporter-junit`, `target/bruno/TEST-{{.CollectionDisplayName}}.xml`, `--reporter-html`, `target/bruno/TEST-{{.CollectionDisplayName}}.html`},
 					},
 					{
-						Name:        "bail",
+						Name:        "brunoSubcommand",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
-						Type:        "bool",
+						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     false,
+						Default:     `run`,
 					},
 					{
-						Name:        "parallel",
+						Name:        "packageManager",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
-						Type:        "bool",
+						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     false,
+						Default:     `npm`,
 					},
 					{
-						Name:        "sandboxMode",
+						Name:        "nodeBinary",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     `safe`,
+						Default:     `node`,
 					},
 					{
-						Name:        "csvFilePath",
+						Name:        "minNodeVersion",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_csvFilePath"),
+						Default:     os.Getenv("PIPER_minNodeVersion"),
 					},
 					{
-						Name:        "jsonFilePath",
+						Name:        "npmBinary",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_jsonFilePath"),
+						Default:     `npm`,
 					},
 					{
-						Name:        "iterationCount",
+						Name:        "brunoInstallCommand",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `npm install @usebruno/cli --global --quiet`,
+					},
+					{
+						Name:        "cleanupInstall",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "installRetries",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "int",
@@ -452,25 +692,52 @@ func brunoExecuteMetadata() config.StepData {
 						Default:     0,
 					},
 					{
-						Name:        "tags",
+						Name:        "brunoVersion",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_tags"),
+						Default:     os.Getenv("PIPER_brunoVersion"),
 					},
 					{
-						Name:        "excludeTags",
+						Name:        "npmRegistry",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_excludeTags"),
+						Default:     os.Getenv("PIPER_npmRegistry"),
 					},
 					{
-						Name:        "testsOnly",
+						Name:        "manageInstallPrefix",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     true,
+					},
+					{
+						Name:        "npmGlobalPrefix",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `~/.npm-global`,
+					},
+					{
+						Name:        "bruBinaryRelPath",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `bin/bru`,
+					},
+					{
+						Name:        "skipInstallIfPresent",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "bool",
@@ -479,34 +746,97 @@ func brunoExecuteMetadata() config.StepData {
 						Default:     false,
 					},
 					{
-						Name:        "reporterJson",
+						Name:        "fallbackToTempPrefix",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "brunoEnvironment",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_reporterJson"),
+						Default:     os.Getenv("PIPER_brunoEnvironment"),
 					},
 					{
-						Name:        "reporterJunit",
+						Name:        "brunoEnvironments",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "forbiddenEnvironments",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "brunoGlobalEnv",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_reporterJunit"),
+						Default:     os.Getenv("PIPER_brunoGlobalEnv"),
 					},
 					{
-						Name:        "reporterHtml",
+						Name:        "envVars",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "envVarsFile",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "string",
 						Mandatory:   false,
 						Aliases:     []config.Alias{},
-						Default:     os.Getenv("PIPER_reporterHtml"),
+						Default:     os.Getenv("PIPER_envVarsFile"),
 					},
 					{
-						Name:        "reporterSkipAllHeaders",
+						Name:        "envVarPrefix",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_envVarPrefix"),
+					},
+					{
+						Name:        "envFile",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_envFile"),
+					},
+					{
+						Name:        "failOnError",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     true,
+					},
+					{
+						Name:        "failOnNoTests",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "bool",
@@ -515,7 +845,7 @@ func brunoExecuteMetadata() config.StepData {
 						Default:     false,
 					},
 					{
-						Name:        "reporterSkipHeaders",
+						Name:        "allowedFailures",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "[]string",
@@ -524,7 +854,25 @@ func brunoExecuteMetadata() config.StepData {
 						Default:     []string{},
 					},
 					{
-						Name:        "delay",
+						Name:        "recursive",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "bail",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "bailAfter",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "int",
@@ -533,7 +881,7 @@ func brunoExecuteMetadata() config.StepData {
 						Default:     0,
 					},
 					{
-						Name:        "insecure",
+						Name:        "parallel",
 						ResourceRef: []config.ResourceReference{},
 						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
 						Type:        "bool",
@@ -541,18 +889,601 @@ func brunoExecuteMetadata() config.StepData {
 						Aliases:     []config.Alias{},
 						Default:     false,
 					},
-				},
-			},
-			Containers: []config.Container{
-				{Name: "bruno", Image: "node:24-bookworm", WorkingDir: "/home/node"},
-			},
-			Outputs: config.StepOutputs{
-				Resources: []config.StepResources{
+					{
+						Name:        "parallelWorkers",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "sandboxMode",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `safe`,
+					},
+					{
+						Name:        "csvFilePath",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_csvFilePath"),
+					},
+					{
+						Name:        "jsonFilePath",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_jsonFilePath"),
+					},
+					{
+						Name:        "dataFilePrecedence",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_dataFilePrecedence"),
+					},
+					{
+						Name:        "iterationCount",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "testFiles",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "tags",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_tags"),
+					},
+					{
+						Name:        "tagsMatchMode",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `all`,
+					},
+					{
+						Name:        "excludeTags",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_excludeTags"),
+					},
+					{
+						Name:        "testsOnly",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "outputFile",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_outputFile"),
+					},
+					{
+						Name:        "successPattern",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_successPattern"),
+					},
+					{
+						Name:        "reportsDirectory",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_reportsDirectory"),
+					},
+					{
+						Name:        "mergeJunitReports",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "mergedJunitPath",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `brunoExecute_junit.xml`,
+					},
+					{
+						Name:        "summaryMarkdownPath",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_summaryMarkdownPath"),
+					},
+					{
+						Name:        "resultsWebhookUrl",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_resultsWebhookUrl"),
+					},
+					{
+						Name:        "reporterJson",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_reporterJson"),
+					},
+					{
+						Name:        "reporterJunit",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_reporterJunit"),
+					},
+					{
+						Name:        "reporterHtml",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_reporterHtml"),
+					},
+					{
+						Name:        "reporterHtmlTemplate",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_reporterHtmlTemplate"),
+					},
+					{
+						Name:        "reporterSkipAllHeaders",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "reporterSkipHeaders",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "reporterSkipResponseHeaders",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "delay",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "requestTimeoutMs",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "insecure",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "insecureHosts",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "disableCookies",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "clientCertConfig",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_clientCertConfig"),
+					},
+					{
+						Name:        "caCert",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_caCert"),
+					},
+					{
+						Name:        "verbose",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "extraFlags",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "timeoutSeconds",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "retries",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "retryDelaySeconds",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "retryBackoff",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     `fixed`,
+					},
+					{
+						Name:        "retryJitter",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "preflightUrl",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_preflightUrl"),
+					},
+					{
+						Name:        "httpProxy",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_httpProxy"),
+					},
+					{
+						Name:        "httpsProxy",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_httpsProxy"),
+					},
+					{
+						Name:        "noProxy",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_noProxy"),
+					},
+					{
+						Name:        "brunoNoProxy",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "versionCheckRetries",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "skipVersionLogging",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "requireCleanCollection",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "maxInstalledPackages",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "allureOutputDir",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_allureOutputDir"),
+					},
+					{
+						Name:        "harOutputPath",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_harOutputPath"),
+					},
+					{
+						Name:        "defaultRunOptions",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "csvResultsOutput",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_csvResultsOutput"),
+					},
+					{
+						Name:        "assertionsOutput",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_assertionsOutput"),
+					},
+					{
+						Name:        "maskUrlQueryParams",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "[]string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     []string{},
+					},
+					{
+						Name:        "failOnDuplicateRequestNames",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "authSmokeRequest",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_authSmokeRequest"),
+					},
+					{
+						Name:        "maxP95ResponseTimeMs",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "slowThresholdMs",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     0,
+					},
+					{
+						Name:        "failOnSlow",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "summarizeFailures",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "includeBodiesOnFailure",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "maxBodyLogBytes",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "int",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     4096,
+					},
+					{
+						Name:        "dryRun",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "bool",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     false,
+					},
+					{
+						Name:        "listOnly",
+						ResourceRef: []config.ResourceReference{},
+						Scope:       []string{"PARAMETERS", "STAGES", "STEPS"},
+						Type:        "string",
+						Mandatory:   false,
+						Aliases:     []config.Alias{},
+						Default:     os.Getenv("PIPER_listOnly"),
+					},
+				},
+			},
+			Containers: []config.Container{
+				{Name: "bruno", Image: "node:24-bookworm", WorkingDir: "/home/node"},
+			},
+			Outputs: config.StepOutputs{
+				Resources: []config.StepResources{
+					{
+						Name: "commonPipelineEnvironment",
+						Type: "piperEnvironment",
+						Parameters: []map[string]interface{}{
+							{"name": "custom/brunoSummary"},
+						},
+					},
 					{
 						Name: "influx",
 						Type: "influx",
 						Parameters: []map[string]interface{}{
-							{"name": "step_data", "fields": []map[string]string{{"name": "bruno"}}},
+							{"name": "step_data", "fields": []map[string]string{{"name": "bruno"}, {"name": "bruno_tests_total"}, {"name": "bruno_tests_failed"}, {"name": "bruno_requests_total"}, {"name": "bruno_duration_ms"}, {"name": "bruno_p95_response_time_ms"}}, "tags": []map[string]string{{"name": "environment"}}},
 						},
 					},
 					{
@@ -0,0 +1,148 @@
+package bruno
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// AllureResult represents a single test result in the format of an Allure results directory
+type AllureResult struct {
+	UUID          string               `json:"uuid"`
+	HistoryID     string               `json:"historyId"`
+	Name          string               `json:"name"`
+	FullName      string               `json:"fullName"`
+	Description   string               `json:"description,omitempty"`
+	Status        string               `json:"status"`
+	StatusDetails *AllureStatusDetails `json:"statusDetails,omitempty"`
+	Stage         string               `json:"stage"`
+	Steps         []AllureStep         `json:"steps"`
+	Attachments   []AllureAttachment   `json:"attachments"`
+	Labels        []AllureLabel        `json:"labels"`
+}
+
+// AllureStatusDetails contains the reason of a failed or broken Allure result
+type AllureStatusDetails struct {
+	Message string `json:"message"`
+}
+
+// AllureStep represents a single assertion or test of a request as Allure step
+type AllureStep struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Stage  string `json:"stage"`
+}
+
+// AllureAttachment references a file within the Allure results directory
+type AllureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+// AllureLabel is a name/value pair used by Allure for grouping results
+type AllureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewAllureResult converts a request result into an Allure result.
+// The index keeps the UUIDs of repeated requests (e.g. of multiple iterations) unique while staying stable across runs.
+// Failed results reference a failure details attachment named <uuid>-attachment.txt, see FailureDetails.
+func NewAllureResult(result Result, index int) AllureResult {
+	fullName := result.Suitename
+	if fullName == "" {
+		fullName = result.Name
+	}
+	id := uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmt.Sprintf("bruno:%v:%v", index, fullName))).String()
+
+	allure := AllureResult{
+		UUID:        id,
+		HistoryID:   uuid.NewSHA1(uuid.NameSpaceURL, []byte("bruno:"+fullName)).String(),
+		Name:        result.Name,
+		FullName:    fullName,
+		Status:      allureStatus(result),
+		Stage:       "finished",
+		Steps:       []AllureStep{},
+		Attachments: []AllureAttachment{},
+		Labels:      []AllureLabel{{Name: "framework", Value: "bruno"}},
+	}
+	if result.Request.Method != "" || result.Request.URL != "" {
+		allure.Description = strings.TrimSpace(result.Request.Method + " " + result.Request.URL)
+	}
+	for _, assertion := range result.AssertionResults {
+		allure.Steps = append(allure.Steps, AllureStep{
+			Name:   strings.TrimSpace(assertion.LhsExpr + " " + assertion.RhsExpr),
+			Status: allureStepStatus(assertion.Status),
+			Stage:  "finished",
+		})
+	}
+	for _, test := range result.TestResults {
+		allure.Steps = append(allure.Steps, AllureStep{
+			Name:   test.Description,
+			Status: allureStepStatus(test.Status),
+			Stage:  "finished",
+		})
+	}
+	if result.Failed() {
+		details := result.FailureDetails()
+		allure.StatusDetails = &AllureStatusDetails{Message: strings.SplitN(details, "\n", 2)[0]}
+		allure.Attachments = append(allure.Attachments, AllureAttachment{
+			Name:   "Failure details",
+			Source: id + "-attachment.txt",
+			Type:   "text/plain",
+		})
+	}
+	return allure
+}
+
+// FailureDetails lists the errors of a request and of its failed assertions and tests, one per line
+func (r Result) FailureDetails() string {
+	details := []string{}
+	if r.Error != nil {
+		details = append(details, fmt.Sprintf("request error: %v", r.Error))
+	}
+	for _, assertion := range r.AssertionResults {
+		if assertion.Status == "fail" {
+			details = append(details, fmt.Sprintf("assertion '%v %v' failed: %v", assertion.LhsExpr, assertion.RhsExpr, errorText(assertion.Error)))
+		}
+	}
+	for _, test := range r.TestResults {
+		if test.Status == "fail" {
+			details = append(details, fmt.Sprintf("test '%v' failed: %v", test.Description, errorText(test.Error)))
+		}
+	}
+	if len(details) == 0 && r.Failed() {
+		details = append(details, fmt.Sprintf("request finished with status '%v'", r.Status))
+	}
+	return strings.Join(details, "\n")
+}
+
+func allureStatus(result Result) string {
+	if result.Status == "error" || result.Error != nil {
+		return "broken"
+	}
+	if result.Failed() {
+		return "failed"
+	}
+	return "passed"
+}
+
+func allureStepStatus(status string) string {
+	switch status {
+	case "pass":
+		return "passed"
+	case "fail":
+		return "failed"
+	default:
+		return "skipped"
+	}
+}
+
+func errorText(err interface{}) string {
+	if err == nil {
+		return "no details available"
+	}
+	return fmt.Sprint(err)
+}
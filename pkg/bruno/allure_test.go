@@ -0,0 +1,64 @@
+//go:build unit
+// +build unit
+
+package bruno
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFixtureResults(t *testing.T) []Result {
+	var iterations []struct {
+		Results []Result `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(readFixture(t, "report.json"), &iterations))
+	return iterations[0].Results
+}
+
+func TestNewAllureResult(t *testing.T) {
+	results := readFixtureResults(t)
+
+	t.Run("passing request", func(t *testing.T) {
+		allure := NewAllureResult(results[0], 0)
+
+		content, err := json.MarshalIndent(allure, "", "  ")
+		require.NoError(t, err)
+		assert.JSONEq(t, string(readFixture(t, filepath.Join("allure", "passing-result.json"))), string(content))
+	})
+
+	t.Run("failing request", func(t *testing.T) {
+		allure := NewAllureResult(results[1], 1)
+
+		content, err := json.MarshalIndent(allure, "", "  ")
+		require.NoError(t, err)
+		assert.JSONEq(t, string(readFixture(t, filepath.Join("allure", "failing-result.json"))), string(content))
+		assert.Equal(t, allure.UUID+"-attachment.txt", allure.Attachments[0].Source)
+	})
+
+	t.Run("request error is broken", func(t *testing.T) {
+		allure := NewAllureResult(Result{Name: "health", Status: "error", Error: "connect ECONNREFUSED"}, 0)
+
+		assert.Equal(t, "broken", allure.Status)
+		assert.Equal(t, "request error: connect ECONNREFUSED", allure.StatusDetails.Message)
+	})
+
+	t.Run("unique per iteration", func(t *testing.T) {
+		first := NewAllureResult(results[0], 0)
+		second := NewAllureResult(results[0], 3)
+
+		assert.NotEqual(t, first.UUID, second.UUID)
+		assert.Equal(t, first.HistoryID, second.HistoryID)
+	})
+}
+
+func TestFailureDetails(t *testing.T) {
+	results := readFixtureResults(t)
+
+	assert.Equal(t, "", results[0].FailureDetails())
+	assert.Equal(t, "assertion 'res.status eq 201' failed: expected 500 to equal 201", results[1].FailureDetails())
+}
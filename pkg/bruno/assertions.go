@@ -0,0 +1,86 @@
+package bruno
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// actualValueRegex extracts the actual value from assertion errors like "expected 401 to equal 200"
+var actualValueRegex = regexp.MustCompile(`^expected (.+?) to `)
+
+// AssertionRecord describes the outcome of a single assertion of a request
+type AssertionRecord struct {
+	Request  string `json:"request"`
+	Name     string `json:"name"`
+	Operator string `json:"operator"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual,omitempty"`
+	Passed   bool   `json:"passed"`
+}
+
+// WriteAssertions streams a Bruno JSON report and writes one JSON line per assertion.
+// The report only contains the actual value of failed assertions, it is taken from the assertion error.
+// Occurrences of the given secrets in the expected and actual values are masked.
+func WriteAssertions(w io.Writer, report io.Reader, secrets []string) error {
+	return ParseReport(report, NewAssertionWriter(w, secrets).Write)
+}
+
+// AssertionWriter writes the assertion records of a report result by result, see WriteAssertions
+type AssertionWriter struct {
+	encoder *json.Encoder
+	secrets []string
+}
+
+// NewAssertionWriter returns an AssertionWriter writing to w
+func NewAssertionWriter(w io.Writer, secrets []string) *AssertionWriter {
+	return &AssertionWriter{encoder: json.NewEncoder(w), secrets: secrets}
+}
+
+// Write writes one JSON line per assertion of a single result
+func (a *AssertionWriter) Write(result Result) error {
+	for _, assertion := range result.AssertionResults {
+		record := AssertionRecord{
+			Request:  result.Name,
+			Name:     assertion.LhsExpr,
+			Operator: assertion.Operator,
+			Expected: maskSecrets(expectedValue(assertion), a.secrets),
+			Actual:   maskSecrets(actualValue(assertion), a.secrets),
+			Passed:   assertion.Status == "pass",
+		}
+		if err := a.encoder.Encode(record); err != nil {
+			return errors.Wrap(err, "failed to write assertion results")
+		}
+	}
+	return nil
+}
+
+func expectedValue(assertion AssertionResult) string {
+	if assertion.RhsOperand != "" {
+		return assertion.RhsOperand
+	}
+	return strings.TrimSpace(strings.TrimPrefix(assertion.RhsExpr, assertion.Operator))
+}
+
+func actualValue(assertion AssertionResult) string {
+	if assertion.Error == nil {
+		return ""
+	}
+	if match := actualValueRegex.FindStringSubmatch(fmt.Sprint(assertion.Error)); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+func maskSecrets(value string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			value = strings.ReplaceAll(value, secret, "****")
+		}
+	}
+	return value
+}
@@ -0,0 +1,38 @@
+//go:build unit
+// +build unit
+
+package bruno
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAssertions(t *testing.T) {
+	t.Run("report fixture with mixed results", func(t *testing.T) {
+		var out bytes.Buffer
+
+		err := WriteAssertions(&out, bytes.NewReader(readFixture(t, "assertions-report.json")), []string{"s3cr3t-token"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, string(readFixture(t, "assertions.jsonl")), out.String())
+	})
+
+	t.Run("report without assertions", func(t *testing.T) {
+		var out bytes.Buffer
+
+		err := WriteAssertions(&out, strings.NewReader(`[{"results": [{"name": "smoke", "status": "pass"}]}]`), nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("malformed report", func(t *testing.T) {
+		err := WriteAssertions(&bytes.Buffer{}, strings.NewReader(`[{"results": [{"name": `), nil)
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,54 @@
+package bruno
+
+import (
+	"strings"
+)
+
+// ParseRequestTags returns the tags of the meta block of a .bru request file.
+// Both the multiline list written by Bruno and a single line list like "tags: [smoke, sanity]" are supported.
+func ParseRequestTags(content string) []string {
+	tags := []string{}
+	inMeta, inTags := false, false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case inTags:
+			if strings.HasPrefix(line, "]") {
+				inTags = false
+				continue
+			}
+			tags = appendTags(tags, line)
+		case inMeta:
+			if line == "}" {
+				return tags
+			}
+			list, found := strings.CutPrefix(line, "tags:")
+			if !found {
+				continue
+			}
+			list = strings.TrimSpace(list)
+			if !strings.HasPrefix(list, "[") {
+				continue
+			}
+			list = strings.TrimPrefix(list, "[")
+			if closed, found := strings.CutSuffix(list, "]"); found {
+				tags = appendTags(tags, closed)
+			} else {
+				inTags = true
+				tags = appendTags(tags, list)
+			}
+		case line == "meta {":
+			inMeta = true
+		}
+	}
+	return tags
+}
+
+func appendTags(tags []string, list string) []string {
+	for _, tag := range strings.Split(list, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,30 @@
+//go:build unit
+// +build unit
+
+package bruno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRequestTags(t *testing.T) {
+	t.Run("multiline list", func(t *testing.T) {
+		content := "meta {\n  name: Get users\n  type: http\n  seq: 1\n  tags: [\n    smoke\n    critical\n  ]\n}\n\nget {\n  url: {{baseUrl}}/users\n}\n"
+
+		assert.Equal(t, []string{"smoke", "critical"}, ParseRequestTags(content))
+	})
+
+	t.Run("single line list", func(t *testing.T) {
+		content := "meta {\n  name: Health\n  tags: [smoke, sanity]\n}\n"
+
+		assert.Equal(t, []string{"smoke", "sanity"}, ParseRequestTags(content))
+	})
+
+	t.Run("without tags", func(t *testing.T) {
+		content := "meta {\n  name: Health\n  type: http\n}\n\nbody:json {\n  {\"tags: [\": \"not a tag\"}\n}\n"
+
+		assert.Empty(t, ParseRequestTags(content))
+	})
+}
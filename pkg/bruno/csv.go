@@ -0,0 +1,69 @@
+package bruno
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+var csvHeader = []string{"request", "method", "url", "status", "duration_ms", "passed"}
+
+// WriteCSV streams a Bruno JSON report and writes a CSV header followed by one row per request result.
+// URLs are sanitized, see SanitizeURL.
+func WriteCSV(w io.Writer, report io.Reader, maskedQueryParams []string) error {
+	if err := WriteCSVHeader(w); err != nil {
+		return err
+	}
+	return WriteCSVRows(w, report, maskedQueryParams)
+}
+
+// WriteCSVHeader writes the header row of the CSV results
+func WriteCSVHeader(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return errors.Wrap(err, "failed to write CSV header")
+	}
+	writer.Flush()
+	return errors.Wrap(writer.Error(), "failed to write CSV header")
+}
+
+// WriteCSVRows streams a Bruno JSON report and writes one CSV row per request result without a header.
+// This allows combining the results of several reports into a single CSV file.
+func WriteCSVRows(w io.Writer, report io.Reader, maskedQueryParams []string) error {
+	writer := NewCSVWriter(w, maskedQueryParams)
+	if err := ParseReport(report, writer.Write); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// CSVWriter writes the CSV rows of a report result by result, see WriteCSVRows
+type CSVWriter struct {
+	writer            *csv.Writer
+	maskedQueryParams []string
+}
+
+// NewCSVWriter returns a CSVWriter writing to w, the rows are buffered until Flush is called
+func NewCSVWriter(w io.Writer, maskedQueryParams []string) *CSVWriter {
+	return &CSVWriter{writer: csv.NewWriter(w), maskedQueryParams: maskedQueryParams}
+}
+
+// Write writes the row of a single result
+func (c *CSVWriter) Write(result Result) error {
+	return c.writer.Write([]string{
+		result.Name,
+		result.Request.Method,
+		SanitizeURL(result.Request.URL, c.maskedQueryParams),
+		strconv.Itoa(result.Response.Status),
+		strconv.FormatInt(result.Response.ResponseTime, 10),
+		strconv.FormatBool(!result.Failed()),
+	})
+}
+
+// Flush writes the buffered rows
+func (c *CSVWriter) Flush() error {
+	c.writer.Flush()
+	return errors.Wrap(c.writer.Error(), "failed to write CSV results")
+}
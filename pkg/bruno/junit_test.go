@@ -0,0 +1,90 @@
+//go:build unit
+// +build unit
+
+package bruno
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJUnitSuites(t *testing.T) {
+	t.Run("testsuites root element", func(t *testing.T) {
+		suites, err := ParseJUnitSuites(readFixture(t, "junit-smoke.xml"))
+
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		assert.Equal(t, "smoke", suites[0].Name)
+		assert.Equal(t, 2, suites[0].Tests)
+		assert.Equal(t, 1, suites[0].Failures)
+		assert.Equal(t, "agent-1", suites[0].Hostname)
+		assert.Contains(t, suites[0].Content, `<failure type="failure" message="expected 3 to equal 2"></failure>`)
+	})
+
+	t.Run("testsuite root element", func(t *testing.T) {
+		suites, err := ParseJUnitSuites(readFixture(t, "junit-regression.xml"))
+
+		require.NoError(t, err)
+		require.Len(t, suites, 1)
+		assert.Equal(t, "regression", suites[0].Name)
+		assert.Equal(t, 1, suites[0].Errors)
+		assert.Equal(t, 1, suites[0].Skipped)
+	})
+
+	t.Run("unexpected root element", func(t *testing.T) {
+		_, err := ParseJUnitSuites([]byte(`<html></html>`))
+
+		assert.EqualError(t, err, "unexpected root element 'html' of JUnit report")
+	})
+
+	t.Run("invalid XML", func(t *testing.T) {
+		_, err := ParseJUnitSuites([]byte(`<testsuites><testsuite`))
+
+		assert.ErrorContains(t, err, "failed to parse JUnit report")
+	})
+}
+
+func TestMergeJUnitSuites(t *testing.T) {
+	smoke, err := ParseJUnitSuites(readFixture(t, "junit-smoke.xml"))
+	require.NoError(t, err)
+	regression, err := ParseJUnitSuites(readFixture(t, "junit-regression.xml"))
+	require.NoError(t, err)
+
+	merged := MergeJUnitSuites(append(smoke, regression...))
+
+	assert.Equal(t, 5, merged.Tests)
+	assert.Equal(t, 1, merged.Failures)
+	assert.Equal(t, 1, merged.Errors)
+	assert.Equal(t, 1, merged.Skipped)
+	assert.Equal(t, 0.3, merged.Time)
+	require.Len(t, merged.Suites, 2)
+
+	t.Run("written report can be parsed again", func(t *testing.T) {
+		var out bytes.Buffer
+
+		require.NoError(t, WriteJUnit(&out, merged))
+
+		assert.Contains(t, out.String(), `<testsuites name="Bruno" tests="5" failures="1" errors="1" skipped="1" time="0.3">`)
+		suites, err := ParseJUnitSuites(out.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, merged.Suites, suites)
+	})
+}
+
+func TestNewCrashJUnitSuites(t *testing.T) {
+	suites, err := NewCrashJUnitSuites("api-tests", "signal: killed <OOM>")
+	require.NoError(t, err)
+	var out bytes.Buffer
+
+	require.NoError(t, WriteJUnit(&out, suites))
+
+	assert.Equal(t, 1, suites.Tests)
+	assert.Equal(t, 1, suites.Errors)
+	assert.Contains(t, out.String(), `<testcase name="Bruno CLI run" classname="api-tests" time="0"><error type="error" message="the Bruno CLI did not write a JUnit report">signal: killed &lt;OOM&gt;</error></testcase>`)
+	parsed, err := ParseJUnitSuites(out.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "api-tests", parsed[0].Name)
+}
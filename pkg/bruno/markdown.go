@@ -0,0 +1,90 @@
+package bruno
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CollectionSummary contains the outcome of the run of a single collection
+type CollectionSummary struct {
+	Name string
+	// RunFailed is set if the Bruno CLI failed, also without a JSON report
+	RunFailed bool
+	// HasReport is set if the metrics were read from a JSON report
+	HasReport bool
+	Metrics   Metrics
+	// SlowRequests exceeded the response time threshold, they do not affect the status
+	SlowRequests []SlowRequest
+}
+
+// Status returns "failed" if the run or one of the requests failed and "passed" otherwise
+func (c CollectionSummary) Status() string {
+	if c.RunFailed || c.Metrics.FailedRequests > 0 {
+		return "failed"
+	}
+	return "passed"
+}
+
+// WriteMarkdownSummary writes a Markdown table with the request counts and durations of the collections, e.g. for pull request comments.
+// Without any JSON report, only the status of the collections is listed.
+func WriteMarkdownSummary(w io.Writer, collections []CollectionSummary) error {
+	var summary strings.Builder
+	summary.WriteString("## Bruno test results\n\n")
+
+	hasReport := false
+	for _, collection := range collections {
+		hasReport = hasReport || collection.HasReport
+	}
+	if !hasReport {
+		summary.WriteString("| Collection | Status |\n| --- | --- |\n")
+		for _, collection := range collections {
+			fmt.Fprintf(&summary, "| %v | %v |\n", markdownCell(collection.Name), collection.Status())
+		}
+		summary.WriteString("\nNo Bruno JSON reports were available, set `reporterJson` to include the request counts.\n")
+		_, err := io.WriteString(w, summary.String())
+		return errors.Wrap(err, "failed to write Markdown summary")
+	}
+
+	summary.WriteString("| Collection | Status | Requests | Passed | Failed | Duration |\n| --- | --- | ---: | ---: | ---: | ---: |\n")
+	total := Metrics{}
+	status := "passed"
+	for _, collection := range collections {
+		if collection.Status() == "failed" {
+			status = "failed"
+		}
+		if !collection.HasReport {
+			fmt.Fprintf(&summary, "| %v | %v | - | - | - | - |\n", markdownCell(collection.Name), collection.Status())
+			continue
+		}
+		total.Merge(collection.Metrics)
+		fmt.Fprintf(&summary, "| %v | %v | %v |\n", markdownCell(collection.Name), collection.Status(), markdownCounts(collection.Metrics))
+	}
+	fmt.Fprintf(&summary, "| **Total** | %v | %v |\n", status, markdownCounts(total))
+	writeMarkdownSlowRequests(&summary, collections)
+	_, err := io.WriteString(w, summary.String())
+	return errors.Wrap(err, "failed to write Markdown summary")
+}
+
+// writeMarkdownSlowRequests lists the slow requests of all collections, nothing is written without slow requests
+func writeMarkdownSlowRequests(summary *strings.Builder, collections []CollectionSummary) {
+	header := "\n### Slow requests\n\n| Collection | Request | Duration |\n| --- | --- | ---: |\n"
+	for _, collection := range collections {
+		for _, request := range collection.SlowRequests {
+			summary.WriteString(header)
+			header = ""
+			fmt.Fprintf(summary, "| %v | %v | %vms |\n", markdownCell(collection.Name), markdownCell(request.Name), request.ResponseTimeMs)
+		}
+	}
+}
+
+func markdownCounts(metrics Metrics) string {
+	return fmt.Sprintf("%v | %v | %v | %vms", metrics.Requests, metrics.Requests-metrics.FailedRequests, metrics.FailedRequests, metrics.DurationMs)
+}
+
+// markdownCell escapes characters which would break the table
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(text)
+}
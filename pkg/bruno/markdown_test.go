@@ -0,0 +1,71 @@
+//go:build unit
+// +build unit
+
+package bruno
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMarkdownSummary(t *testing.T) {
+	t.Run("collections with reports", func(t *testing.T) {
+		metrics, err := ReadMetrics(bytes.NewReader(readFixture(t, "report.json")))
+		require.NoError(t, err)
+		collections := []CollectionSummary{
+			{Name: "smoke", HasReport: true, Metrics: Metrics{Requests: 2, DurationMs: 40}},
+			{Name: "regression", RunFailed: true, HasReport: true, Metrics: metrics},
+			{Name: "contract|v2", RunFailed: true},
+		}
+		var out bytes.Buffer
+
+		err = WriteMarkdownSummary(&out, collections)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "## Bruno test results\n\n"+
+			"| Collection | Status | Requests | Passed | Failed | Duration |\n"+
+			"| --- | --- | ---: | ---: | ---: | ---: |\n"+
+			"| smoke | passed | 2 | 2 | 0 | 40ms |\n"+
+			"| regression | failed | 3 | 2 | 1 | 215ms |\n"+
+			"| contract\\|v2 | failed | - | - | - | - |\n"+
+			"| **Total** | failed | 5 | 4 | 1 | 255ms |\n", out.String())
+	})
+
+	t.Run("with slow requests", func(t *testing.T) {
+		collections := []CollectionSummary{
+			{Name: "smoke", HasReport: true, Metrics: Metrics{Requests: 2, DurationMs: 40}},
+			{Name: "regression", HasReport: true, Metrics: Metrics{Requests: 1, DurationMs: 900}, SlowRequests: []SlowRequest{{Name: "get-users", ResponseTimeMs: 900}}},
+		}
+		var out bytes.Buffer
+
+		err := WriteMarkdownSummary(&out, collections)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "## Bruno test results\n\n"+
+			"| Collection | Status | Requests | Passed | Failed | Duration |\n"+
+			"| --- | --- | ---: | ---: | ---: | ---: |\n"+
+			"| smoke | passed | 2 | 2 | 0 | 40ms |\n"+
+			"| regression | passed | 1 | 1 | 0 | 900ms |\n"+
+			"| **Total** | passed | 3 | 3 | 0 | 940ms |\n"+
+			"\n### Slow requests\n\n"+
+			"| Collection | Request | Duration |\n"+
+			"| --- | --- | ---: |\n"+
+			"| regression | get-users | 900ms |\n", out.String())
+	})
+
+	t.Run("without reports", func(t *testing.T) {
+		var out bytes.Buffer
+
+		err := WriteMarkdownSummary(&out, []CollectionSummary{{Name: "smoke"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "## Bruno test results\n\n"+
+			"| Collection | Status |\n"+
+			"| --- | --- |\n"+
+			"| smoke | passed |\n"+
+			"\nNo Bruno JSON reports were available, set `reporterJson` to include the request counts.\n", out.String())
+	})
+}
@@ -0,0 +1,459 @@
+package bruno
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Result represents the outcome of a single request within a Bruno JSON report
+type Result struct {
+	Name             string            `json:"name"`
+	Suitename        string            `json:"suitename"`
+	Status           string            `json:"status"`
+	Error            interface{}       `json:"error"`
+	Request          Request           `json:"request"`
+	Response         Response          `json:"response"`
+	AssertionResults []AssertionResult `json:"assertionResults"`
+	TestResults      []TestResult      `json:"testResults"`
+	Runtime          float64           `json:"runtime"`
+	IterationIndex   int               `json:"iterationIndex"`
+}
+
+// Request contains the request details of a result
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Data   interface{} `json:"data"`
+}
+
+// Response contains the response details of a result
+type Response struct {
+	Status       int         `json:"status"`
+	ResponseTime int64       `json:"responseTime"`
+	Data         interface{} `json:"data"`
+}
+
+// AssertionResult represents a single assertion evaluated for a request
+type AssertionResult struct {
+	LhsExpr    string      `json:"lhsExpr"`
+	RhsExpr    string      `json:"rhsExpr"`
+	RhsOperand string      `json:"rhsOperand"`
+	Operator   string      `json:"operator"`
+	Status     string      `json:"status"`
+	Error      interface{} `json:"error"`
+}
+
+// TestResult represents a single script test evaluated for a request
+type TestResult struct {
+	Description string      `json:"description"`
+	Status      string      `json:"status"`
+	Error       interface{} `json:"error"`
+}
+
+// Failed returns true if the request itself errored or any of its assertions or tests failed
+func (r Result) Failed() bool {
+	if r.Status == "fail" || r.Status == "error" || r.Error != nil {
+		return true
+	}
+	for _, assertion := range r.AssertionResults {
+		if assertion.Status == "fail" {
+			return true
+		}
+	}
+	for _, test := range r.TestResults {
+		if test.Status == "fail" {
+			return true
+		}
+	}
+	return false
+}
+
+// Metrics contains the aggregated numbers of a Bruno JSON report
+type Metrics struct {
+	Requests         int
+	FailedRequests   int
+	Tests            int
+	FailedTests      int
+	Assertions       int
+	FailedAssertions int
+	DurationMs       int64
+	// AllowedFailures counts the failed requests which are allowed to fail, they are not counted as failed
+	AllowedFailures int
+}
+
+// Add accumulates a single result into the metrics
+func (m *Metrics) Add(result Result) {
+	m.Requests++
+	if result.Failed() {
+		m.FailedRequests++
+	}
+	for _, test := range result.TestResults {
+		m.Tests++
+		if test.Status == "fail" {
+			m.FailedTests++
+		}
+	}
+	for _, assertion := range result.AssertionResults {
+		m.Assertions++
+		if assertion.Status == "fail" {
+			m.FailedAssertions++
+		}
+	}
+	m.DurationMs += result.Response.ResponseTime
+}
+
+// AddAllowed accumulates a result whose failure is allowed, its request, tests and assertions are counted without failures
+func (m *Metrics) AddAllowed(result Result) {
+	m.Requests++
+	if result.Failed() {
+		m.AllowedFailures++
+	}
+	m.Tests += len(result.TestResults)
+	m.Assertions += len(result.AssertionResults)
+	m.DurationMs += result.Response.ResponseTime
+}
+
+// Merge accumulates the metrics of another report, e.g. of a further collection
+func (m *Metrics) Merge(other Metrics) {
+	m.Requests += other.Requests
+	m.FailedRequests += other.FailedRequests
+	m.Tests += other.Tests
+	m.FailedTests += other.FailedTests
+	m.Assertions += other.Assertions
+	m.FailedAssertions += other.FailedAssertions
+	m.DurationMs += other.DurationMs
+	m.AllowedFailures += other.AllowedFailures
+}
+
+// ReadMetrics streams a Bruno JSON report and aggregates its metrics
+func ReadMetrics(r io.Reader) (Metrics, error) {
+	return ReadMetricsWithAllowedFailures(r, nil)
+}
+
+// ReadMetricsWithAllowedFailures streams a Bruno JSON report and aggregates its metrics,
+// the failures of the requests named in allowedFailures are counted as AllowedFailures instead of failures
+func ReadMetricsWithAllowedFailures(r io.Reader, allowedFailures []string) (Metrics, error) {
+	metrics := Metrics{}
+	err := ParseReport(r, func(result Result) error {
+		if slices.Contains(allowedFailures, result.Name) {
+			metrics.AddAllowed(result)
+		} else {
+			metrics.Add(result)
+		}
+		return nil
+	})
+	return metrics, err
+}
+
+// ReadResponseTimes streams a Bruno JSON report and returns the response times of all requests in milliseconds
+func ReadResponseTimes(r io.Reader) ([]int64, error) {
+	responseTimes := []int64{}
+	err := ParseReport(r, func(result Result) error {
+		responseTimes = append(responseTimes, result.Response.ResponseTime)
+		return nil
+	})
+	return responseTimes, err
+}
+
+// SlowRequest is a request whose response time exceeded a threshold
+type SlowRequest struct {
+	Name           string
+	ResponseTimeMs int64
+}
+
+// FindSlowRequests streams a Bruno JSON report and returns the requests with a response time above thresholdMs in the order of the report
+func FindSlowRequests(r io.Reader, thresholdMs int64) ([]SlowRequest, error) {
+	slow := []SlowRequest{}
+	err := ParseReport(r, func(result Result) error {
+		if result.Response.ResponseTime > thresholdMs {
+			slow = append(slow, SlowRequest{Name: result.Name, ResponseTimeMs: result.Response.ResponseTime})
+		}
+		return nil
+	})
+	return slow, err
+}
+
+// Percentile returns the nearest-rank percentile p (0 < p <= 100) of the values, or 0 if there are no values
+func Percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// SummarizeFailures streams a Bruno JSON report and returns a concise description per failed request,
+// naming the request and the messages of its failed assertions and tests. URLs are sanitized, see SanitizeURL.
+// With maxBodyBytes above 0, the request and response bodies are appended as well, truncated to maxBodyBytes.
+func SummarizeFailures(r io.Reader, maskedQueryParams []string, maxBodyBytes int) ([]string, error) {
+	summary := []string{}
+	err := ParseReport(r, func(result Result) error {
+		if result.Failed() {
+			summary = append(summary, result.FailureSummary(maskedQueryParams, maxBodyBytes))
+		}
+		return nil
+	})
+	return summary, err
+}
+
+// FailureSummary returns a concise description of a failed request on a single line, see SummarizeFailures
+func (r Result) FailureSummary(maskedQueryParams []string, maxBodyBytes int) string {
+	request := r.Name
+	if r.Request.URL != "" {
+		request = fmt.Sprintf("%v (%v %v)", r.Name, r.Request.Method, SanitizeURL(r.Request.URL, maskedQueryParams))
+	}
+	details := strings.ReplaceAll(r.FailureDetails(), "\n", "; ")
+	if maxBodyBytes > 0 {
+		if body := FormatBody(r.Request.Data, maxBodyBytes); body != "" {
+			details += "; request body: " + body
+		}
+		if body := FormatBody(r.Response.Data, maxBodyBytes); body != "" {
+			details += "; response body: " + body
+		}
+	}
+	return fmt.Sprintf("%v: %v", request, details)
+}
+
+// FormatBody returns a body of a Bruno JSON report on a single line for logging, truncated to maxBytes.
+// JSON bodies are reported as objects, which are serialized compactly. Binary content is only described by its size.
+func FormatBody(data interface{}, maxBytes int) string {
+	var body string
+	switch value := data.(type) {
+	case nil:
+		return ""
+	case string:
+		body = value
+	default:
+		content, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("<unserializable body: %v>", err)
+		}
+		body = string(content)
+	}
+	if body == "" {
+		return ""
+	}
+	if !utf8.ValidString(body) || strings.ContainsRune(body, 0) {
+		return fmt.Sprintf("<binary content, %v bytes>", len(body))
+	}
+	body = strings.NewReplacer("\r", "\\r", "\n", "\\n").Replace(body)
+	if len(body) <= maxBytes {
+		return body
+	}
+	truncated := body[:maxBytes]
+	// do not cut a multi-byte character in half
+	for !utf8.ValidString(truncated) {
+		truncated = truncated[:len(truncated)-1]
+	}
+	return fmt.Sprintf("%v... (truncated, %v bytes)", truncated, len(body))
+}
+
+// Causes of failed requests determined by ClassifyFailures
+const (
+	FailureCauseUnknown        = ""
+	FailureCauseTest           = "test"
+	FailureCauseInfrastructure = "infrastructure"
+)
+
+// networkErrorPatterns identify request errors caused by connectivity problems instead of the tested API
+var networkErrorPatterns = []string{"ECONNREFUSED", "ECONNRESET", "ENOTFOUND", "EAI_AGAIN", "ETIMEDOUT", "EHOSTUNREACH", "ENETUNREACH", "getaddrinfo", "socket hang up"}
+
+// ClassifyFailures streams a Bruno JSON report and determines the cause of its failed requests on a best-effort basis.
+// Network errors of any request take precedence over failed assertions and tests, FailureCauseUnknown is returned if nothing failed.
+func ClassifyFailures(r io.Reader) (string, error) {
+	classifier := FailureClassifier{}
+	err := ParseReport(r, func(result Result) error {
+		classifier.Add(result)
+		return nil
+	})
+	return classifier.Cause, err
+}
+
+// FailureClassifier determines the cause of the failed requests of a report result by result, see ClassifyFailures
+type FailureClassifier struct {
+	Cause string
+}
+
+// Add takes the outcome of a single result into account
+func (c *FailureClassifier) Add(result Result) {
+	if result.Error != nil && isNetworkError(fmt.Sprint(result.Error)) {
+		c.Cause = FailureCauseInfrastructure
+	} else if result.Failed() && c.Cause == FailureCauseUnknown {
+		c.Cause = FailureCauseTest
+	}
+}
+
+func isNetworkError(message string) bool {
+	for _, pattern := range networkErrorPatterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindFailedNames streams a Bruno JSON report and returns the names of the failed requests in the order of the report.
+// Requests failing in several iterations are only listed once.
+func FindFailedNames(r io.Reader) ([]string, error) {
+	failed := FailedNames{}
+	err := ParseReport(r, func(result Result) error {
+		failed.Add(result)
+		return nil
+	})
+	return failed.Names(), err
+}
+
+// FailedNames collects the names of the failed requests of a report result by result, see FindFailedNames
+type FailedNames struct {
+	names []string
+	seen  map[string]bool
+}
+
+// Add records the name of the result if it failed
+func (f *FailedNames) Add(result Result) {
+	if !result.Failed() || f.seen[result.Name] {
+		return
+	}
+	if f.seen == nil {
+		f.seen = map[string]bool{}
+	}
+	f.seen[result.Name] = true
+	f.names = append(f.names, result.Name)
+}
+
+// Names returns the names of the failed requests in the order of the report
+func (f *FailedNames) Names() []string {
+	return append([]string{}, f.names...)
+}
+
+// FindDuplicateNames streams a Bruno JSON report and returns the sorted names of requests which occur more than once within an iteration
+func FindDuplicateNames(r io.Reader) ([]string, error) {
+	duplicates := DuplicateNames{}
+	err := ParseReport(r, func(result Result) error {
+		duplicates.Add(result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return duplicates.Names(), nil
+}
+
+// DuplicateNames collects the names of requests occurring more than once within an iteration result by result, see FindDuplicateNames
+type DuplicateNames struct {
+	seen       map[int]map[string]bool
+	duplicates map[string]bool
+}
+
+// Add records the name of the result within its iteration
+func (d *DuplicateNames) Add(result Result) {
+	if d.seen == nil {
+		d.seen = map[int]map[string]bool{}
+		d.duplicates = map[string]bool{}
+	}
+	if d.seen[result.IterationIndex] == nil {
+		d.seen[result.IterationIndex] = map[string]bool{}
+	}
+	if d.seen[result.IterationIndex][result.Name] {
+		d.duplicates[result.Name] = true
+	}
+	d.seen[result.IterationIndex][result.Name] = true
+}
+
+// Names returns the sorted names of the duplicate requests
+func (d *DuplicateNames) Names() []string {
+	names := make([]string, 0, len(d.duplicates))
+	for name := range d.duplicates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseReport reads a Bruno JSON report token by token and calls handle for every request result.
+// Only a single result is held in memory at a time, so reports of arbitrary size can be processed.
+// Both the list of iterations written by current CLI versions and a single report object are supported.
+func ParseReport(r io.Reader, handle func(Result) error) error {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return errors.Wrap(err, "failed to read Bruno report")
+	}
+	switch token {
+	case json.Delim('['):
+		for decoder.More() {
+			if err := expectDelim(decoder, '{'); err != nil {
+				return err
+			}
+			if err := parseIteration(decoder, handle); err != nil {
+				return err
+			}
+		}
+		return expectDelim(decoder, ']')
+	case json.Delim('{'):
+		return parseIteration(decoder, handle)
+	default:
+		return fmt.Errorf("unexpected token '%v' at the start of the Bruno report", token)
+	}
+}
+
+// parseIteration expects the opening brace of the iteration object to be consumed already
+func parseIteration(decoder *json.Decoder, handle func(Result) error) error {
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return errors.Wrap(err, "failed to read Bruno report")
+		}
+		if key, _ := token.(string); key != "results" {
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return errors.Wrapf(err, "failed to read '%v' of Bruno report", token)
+			}
+			continue
+		}
+		if err := expectDelim(decoder, '['); err != nil {
+			return err
+		}
+		for decoder.More() {
+			var result Result
+			if err := decoder.Decode(&result); err != nil {
+				return errors.Wrap(err, "failed to read result of Bruno report")
+			}
+			if err := handle(result); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return errors.Wrap(err, "failed to read Bruno report")
+	}
+	if token != delim {
+		return fmt.Errorf("unexpected token '%v' in Bruno report, expected '%v'", token, delim)
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+//go:build unit
+// +build unit
+
+package bruno
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return content
+}
+
+func TestReadMetrics(t *testing.T) {
+	t.Run("iteration list", func(t *testing.T) {
+		metrics, err := ReadMetrics(strings.NewReader(string(readFixture(t, "report.json"))))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Metrics{
+			Requests:         3,
+			FailedRequests:   1,
+			Tests:            2,
+			FailedTests:      0,
+			Assertions:       3,
+			FailedAssertions: 1,
+			DurationMs:       215,
+		}, metrics)
+	})
+
+	t.Run("single report object", func(t *testing.T) {
+		report := `{"summary": {"totalRequests": 1}, "results": [{"name": "health", "status": "pass", "response": {"responseTime": 10}}]}`
+
+		metrics, err := ReadMetrics(strings.NewReader(report))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Metrics{Requests: 1, DurationMs: 10}, metrics)
+	})
+
+	t.Run("request error counts as failure", func(t *testing.T) {
+		report := `[{"results": [{"name": "health", "status": "error", "error": "connect ECONNREFUSED"}]}]`
+
+		metrics, err := ReadMetrics(strings.NewReader(report))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, metrics.FailedRequests)
+	})
+
+	t.Run("matches batch parsing", func(t *testing.T) {
+		content := readFixture(t, "report.json")
+		var iterations []struct {
+			Results []Result `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(content, &iterations))
+		expected := Metrics{}
+		for _, iteration := range iterations {
+			for _, result := range iteration.Results {
+				expected.Add(result)
+			}
+		}
+
+		metrics, err := ReadMetrics(strings.NewReader(string(content)))
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, metrics)
+	})
+
+	t.Run("malformed report", func(t *testing.T) {
+		_, err := ReadMetrics(strings.NewReader(`[{"results": [{"name": `))
+
+		assert.Error(t, err)
+	})
+
+	t.Run("unexpected content", func(t *testing.T) {
+		_, err := ReadMetrics(strings.NewReader(`"report"`))
+
+		assert.EqualError(t, err, "unexpected token 'report' at the start of the Bruno report")
+	})
+}
+
+func TestReadMetricsWithAllowedFailures(t *testing.T) {
+	report := `[{"results": [
+		{"name": "health", "status": "pass", "response": {"responseTime": 10}},
+		{"name": "flaky", "status": "fail", "response": {"responseTime": 20}, "assertionResults": [{"status": "fail"}], "testResults": [{"status": "fail"}]},
+		{"name": "users", "status": "fail", "response": {"responseTime": 30}, "assertionResults": [{"status": "fail"}]}
+	]}]`
+
+	metrics, err := ReadMetricsWithAllowedFailures(strings.NewReader(report), []string{"flaky"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, Metrics{
+		Requests:         3,
+		FailedRequests:   1,
+		Tests:            1,
+		Assertions:       2,
+		FailedAssertions: 1,
+		DurationMs:       60,
+		AllowedFailures:  1,
+	}, metrics)
+}
+
+func TestMetricsMerge(t *testing.T) {
+	metrics := Metrics{Requests: 2, FailedRequests: 1, Tests: 3, Assertions: 4, FailedAssertions: 1, DurationMs: 100}
+
+	metrics.Merge(Metrics{Requests: 1, Tests: 1, FailedTests: 1, Assertions: 2, DurationMs: 20})
+
+	assert.Equal(t, Metrics{Requests: 3, FailedRequests: 1, Tests: 4, FailedTests: 1, Assertions: 6, FailedAssertions: 1, DurationMs: 120}, metrics)
+}
+
+func TestReadResponseTimes(t *testing.T) {
+	responseTimes, err := ReadResponseTimes(strings.NewReader(string(readFixture(t, "report.json"))))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{120, 80, 15}, responseTimes)
+}
+
+func TestFindSlowRequests(t *testing.T) {
+	slow, err := FindSlowRequests(strings.NewReader(string(readFixture(t, "report.json"))), 50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []SlowRequest{{Name: "get-users", ResponseTimeMs: 120}, {Name: "create-user", ResponseTimeMs: 80}}, slow)
+}
+
+func TestPercentile(t *testing.T) {
+	values := []int64{}
+	for i := int64(100); i > 0; i-- {
+		values = append(values, i*10)
+	}
+
+	assert.Equal(t, int64(950), Percentile(values, 95))
+	assert.Equal(t, int64(500), Percentile(values, 50))
+	assert.Equal(t, int64(1000), Percentile(values, 100))
+	assert.Equal(t, int64(120), Percentile([]int64{120, 80, 15}, 95))
+	assert.Equal(t, int64(0), Percentile([]int64{}, 95))
+	assert.Equal(t, int64(1000), values[0], "input must not be sorted in place")
+}
+
+func TestSummarizeFailures(t *testing.T) {
+	t.Run("mixed results", func(t *testing.T) {
+		report := `[{"results": [
+			{"name": "health", "status": "pass"},
+			{"name": "create-user", "status": "fail", "request": {"method": "POST", "url": "https://api.example.com/users?token=abc"}, "assertionResults": [
+				{"lhsExpr": "res.status", "rhsExpr": "eq 201", "status": "fail", "error": "expected 500 to equal 201"},
+				{"lhsExpr": "res.body.id", "rhsExpr": "isDefined", "status": "fail", "error": "expected undefined to be defined"}
+			]},
+			{"name": "delete-user", "status": "fail", "testResults": [{"description": "returns 204", "status": "fail", "error": "expected 404 to equal 204"}]}
+		]}]`
+
+		summary, err := SummarizeFailures(strings.NewReader(report), []string{"token"}, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"create-user (POST https://api.example.com/users?token=****): assertion 'res.status eq 201' failed: expected 500 to equal 201; assertion 'res.body.id isDefined' failed: expected undefined to be defined",
+			"delete-user: test 'returns 204' failed: expected 404 to equal 204",
+		}, summary)
+	})
+
+	t.Run("with bodies", func(t *testing.T) {
+		report := `[{"results": [
+			{"name": "create-user", "status": "fail", "request": {"method": "POST", "url": "https://api.example.com/users", "data": {"name": "tester"}},
+				"response": {"status": 500, "data": "internal server error while creating the user"},
+				"testResults": [{"description": "returns 201", "status": "fail", "error": "expected 500 to equal 201"}]}
+		]}]`
+
+		summary, err := SummarizeFailures(strings.NewReader(report), nil, 20)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"create-user (POST https://api.example.com/users): test 'returns 201' failed: expected 500 to equal 201; request body: {\"name\":\"tester\"}; response body: internal server erro... (truncated, 45 bytes)",
+		}, summary)
+	})
+
+	t.Run("no failures", func(t *testing.T) {
+		summary, err := SummarizeFailures(strings.NewReader(`[{"results": [{"name": "health", "status": "pass"}]}]`), nil, 0)
+
+		assert.NoError(t, err)
+		assert.Empty(t, summary)
+	})
+
+	t.Run("malformed report", func(t *testing.T) {
+		_, err := SummarizeFailures(strings.NewReader(`[{"results": [{"name": `), nil, 0)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatBody(t *testing.T) {
+	assert.Equal(t, "", FormatBody(nil, 10))
+	assert.Equal(t, "", FormatBody("", 10))
+	assert.Equal(t, "ok", FormatBody("ok", 10))
+	assert.Equal(t, `{"id":1}`, FormatBody(map[string]interface{}{"id": 1}, 10))
+	assert.Equal(t, `line 1\nline 2`, FormatBody("line 1\nline 2", 20))
+	assert.Equal(t, "abcde... (truncated, 10 bytes)", FormatBody("abcdefghij", 5))
+	assert.Equal(t, "ä... (truncated, 5 bytes)", FormatBody("ääx", 3), "multi-byte characters must not be split")
+	assert.Equal(t, "<binary content, 4 bytes>", FormatBody("\x89PNG", 10))
+}
+
+func TestClassifyFailures(t *testing.T) {
+	t.Run("assertion failures", func(t *testing.T) {
+		report := `[{"results": [
+			{"name": "login", "status": "pass"},
+			{"name": "get user", "status": "fail", "assertionResults": [{"lhsExpr": "res.status", "rhsExpr": "eq 200", "status": "fail", "error": "expected 404 to equal 200"}]}
+		]}]`
+
+		cause, err := ClassifyFailures(strings.NewReader(report))
+
+		assert.NoError(t, err)
+		assert.Equal(t, FailureCauseTest, cause)
+	})
+
+	t.Run("network errors", func(t *testing.T) {
+		report := `[{"results": [
+			{"name": "get user", "status": "fail", "assertionResults": [{"lhsExpr": "res.status", "rhsExpr": "eq 200", "status": "fail"}]},
+			{"name": "login", "status": "error", "error": "getaddrinfo ENOTFOUND api.example.com"}
+		]}]`
+
+		cause, err := ClassifyFailures(strings.NewReader(report))
+
+		assert.NoError(t, err)
+		assert.Equal(t, FailureCauseInfrastructure, cause)
+	})
+
+	t.Run("no failures", func(t *testing.T) {
+		cause, err := ClassifyFailures(strings.NewReader(`[{"results": [{"name": "login", "status": "pass"}]}]`))
+
+		assert.NoError(t, err)
+		assert.Equal(t, FailureCauseUnknown, cause)
+	})
+}
+
+func TestFindFailedNames(t *testing.T) {
+	t.Run("failed requests of multiple iterations", func(t *testing.T) {
+		report := `[
+			{"results": [{"name": "login", "status": "fail"}, {"name": "health", "status": "pass"}, {"name": "get user", "status": "error"}]},
+			{"results": [{"name": "login", "status": "fail"}, {"name": "health", "status": "pass"}]}
+		]`
+
+		names, err := FindFailedNames(strings.NewReader(report))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"login", "get user"}, names)
+	})
+
+	t.Run("malformed report", func(t *testing.T) {
+		_, err := FindFailedNames(strings.NewReader(`[{"results": [{"name": `))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestFindDuplicateNames(t *testing.T) {
+	t.Run("no duplicates", func(t *testing.T) {
+		duplicates, err := FindDuplicateNames(strings.NewReader(string(readFixture(t, "report.json"))))
+
+		assert.NoError(t, err)
+		assert.Empty(t, duplicates)
+	})
+
+	t.Run("duplicates within an iteration", func(t *testing.T) {
+		report := `[{"results": [
+			{"name": "login", "suitename": "auth/login", "iterationIndex": 0},
+			{"name": "get", "suitename": "users/get", "iterationIndex": 0},
+			{"name": "login", "suitename": "admin/login", "iterationIndex": 0},
+			{"name": "get", "suitename": "orders/get", "iterationIndex": 0},
+			{"name": "health", "suitename": "health", "iterationIndex": 0}
+		]}]`
+
+		duplicates, err := FindDuplicateNames(strings.NewReader(report))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"get", "login"}, duplicates)
+	})
+
+	t.Run("repeated requests of multiple iterations", func(t *testing.T) {
+		report := `[{"results": [{"name": "health", "iterationIndex": 0}]}, {"results": [{"name": "health", "iterationIndex": 1}]}]`
+
+		duplicates, err := FindDuplicateNames(strings.NewReader(report))
+
+		assert.NoError(t, err)
+		assert.Empty(t, duplicates)
+	})
+}
+
+func TestParseReportLarge(t *testing.T) {
+	const resultCount = 200000
+	result := `{"name": "request %d", "status": "%s", "request": {"method": "GET", "url": "https://api.example.com/items/%d"}, "response": {"status": 200, "responseTime": 1, "data": "` + strings.Repeat("x", 256) + `"}, "assertionResults": [{"lhsExpr": "res.status", "rhsExpr": "eq 200", "operator": "eq", "status": "%s"}]}`
+
+	// generate the report on the fly so that it is never held in memory as a whole
+	reader, writer := io.Pipe()
+	go func() {
+		fmt.Fprint(writer, `[{"iterationIndex": 0, "summary": {}, "results": [`)
+		for i := 0; i < resultCount; i++ {
+			if i > 0 {
+				fmt.Fprint(writer, ",")
+			}
+			status := "pass"
+			if i%10 == 0 {
+				status = "fail"
+			}
+			fmt.Fprintf(writer, result, i, status, i, status)
+		}
+		fmt.Fprint(writer, `]}]`)
+		writer.Close()
+	}()
+
+	runtime.GC()
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	baseline := memStats.HeapAlloc
+	peak := baseline
+
+	metrics := Metrics{}
+	count := 0
+	err := ParseReport(reader, func(result Result) error {
+		metrics.Add(result)
+		count++
+		if count%10000 == 0 {
+			runtime.ReadMemStats(&memStats)
+			if memStats.HeapAlloc > peak {
+				peak = memStats.HeapAlloc
+			}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, resultCount, metrics.Requests)
+	assert.Equal(t, resultCount/10, metrics.FailedRequests)
+	assert.Equal(t, resultCount, metrics.Assertions)
+	assert.Equal(t, resultCount/10, metrics.FailedAssertions)
+	assert.Equal(t, int64(resultCount), metrics.DurationMs)
+	// the generated report has a size of roughly 80MB
+	assert.Less(t, peak-baseline, uint64(32*1024*1024), "heap grew beyond the expected bound while streaming the report")
+}
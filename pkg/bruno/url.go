@@ -0,0 +1,44 @@
+package bruno
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SanitizeURL removes credentials contained in the user info of a URL and masks the values of the given query parameters.
+// Query parameter names are matched case-insensitively. URLs which cannot be parsed (e.g. unresolved
+// Bruno variables) still have their query parameters masked.
+func SanitizeURL(rawURL string, maskedQueryParams []string) string {
+	sanitized := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.User != nil {
+		parsed.User = nil
+		sanitized = parsed.String()
+	}
+	if len(maskedQueryParams) == 0 {
+		return sanitized
+	}
+
+	queryStart := strings.Index(sanitized, "?")
+	if queryStart < 0 {
+		return sanitized
+	}
+	query, fragment := sanitized[queryStart+1:], ""
+	if fragmentStart := strings.Index(query, "#"); fragmentStart >= 0 {
+		query, fragment = query[:fragmentStart], query[fragmentStart:]
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		name, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		for _, masked := range maskedQueryParams {
+			if strings.EqualFold(name, masked) {
+				key, _, _ := strings.Cut(pair, "=")
+				pairs[i] = key + "=****"
+				break
+			}
+		}
+	}
+	return sanitized[:queryStart+1] + strings.Join(pairs, "&") + fragment
+}
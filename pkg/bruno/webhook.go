@@ -0,0 +1,38 @@
+package bruno
+
+// WebhookPayload is the JSON document posted to a results webhook after the run
+type WebhookPayload struct {
+	Status      string              `json:"status"`
+	Passed      int                 `json:"passed"`
+	Failed      int                 `json:"failed"`
+	Collections []WebhookCollection `json:"collections"`
+}
+
+// WebhookCollection contains the outcome of a single collection within the WebhookPayload
+type WebhookCollection struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Passed int    `json:"passed"`
+	Failed int    `json:"failed"`
+}
+
+// NewWebhookPayload returns the passed and failed request counts of the collections and the overall status,
+// which is "failed" if any of the collections failed
+func NewWebhookPayload(collections []CollectionSummary) WebhookPayload {
+	payload := WebhookPayload{Status: "passed", Collections: []WebhookCollection{}}
+	for _, collection := range collections {
+		passed := collection.Metrics.Requests - collection.Metrics.FailedRequests
+		payload.Collections = append(payload.Collections, WebhookCollection{
+			Name:   collection.Name,
+			Status: collection.Status(),
+			Passed: passed,
+			Failed: collection.Metrics.FailedRequests,
+		})
+		payload.Passed += passed
+		payload.Failed += collection.Metrics.FailedRequests
+		if collection.Status() == "failed" {
+			payload.Status = "failed"
+		}
+	}
+	return payload
+}
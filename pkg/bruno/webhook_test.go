@@ -0,0 +1,44 @@
+//go:build unit
+// +build unit
+
+package bruno
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWebhookPayload(t *testing.T) {
+	t.Run("failed collection", func(t *testing.T) {
+		collections := []CollectionSummary{
+			{Name: "smoke", HasReport: true, Metrics: Metrics{Requests: 2}},
+			{Name: "regression", RunFailed: true, HasReport: true, Metrics: Metrics{Requests: 3, FailedRequests: 1}},
+		}
+
+		payload := NewWebhookPayload(collections)
+
+		assert.Equal(t, WebhookPayload{
+			Status: "failed",
+			Passed: 4,
+			Failed: 1,
+			Collections: []WebhookCollection{
+				{Name: "smoke", Status: "passed", Passed: 2, Failed: 0},
+				{Name: "regression", Status: "failed", Passed: 2, Failed: 1},
+			},
+		}, payload)
+	})
+
+	t.Run("passed collections", func(t *testing.T) {
+		payload := NewWebhookPayload([]CollectionSummary{{Name: "smoke", HasReport: true, Metrics: Metrics{Requests: 2}}})
+
+		assert.Equal(t, "passed", payload.Status)
+		assert.Equal(t, 2, payload.Passed)
+	})
+
+	t.Run("without collections", func(t *testing.T) {
+		payload := NewWebhookPayload(nil)
+
+		assert.Equal(t, WebhookPayload{Status: "passed", Collections: []WebhookCollection{}}, payload)
+	})
+}